@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/parse"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/store"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+func cmdClassify(c *cli.Context) error {
+	ctx := c.Context
+
+	dbFilePath := filepath.Join(config.Path.DataDir, filenameDatabase)
+
+	if err := store.Setup(ctx, dbFilePath, false); err != nil {
+		return xerrors.Errorf("Failed to setup database: %+w", err)
+	}
+
+	db, err := store.Open(dbFilePath)
+	if err != nil {
+		return xerrors.Errorf("Failed to open database: %+w", err)
+	}
+	defer db.Close()
+
+	races, err := store.SelectRacesToClassify(ctx, db)
+	if err != nil {
+		return xerrors.Errorf("Failed to select races: %+w", err)
+	}
+
+	records := []*parse.RaceClassification{}
+	for i := 0; i < len(races); i++ {
+		records = append(records, parse.ClassifyRace(races[i], config.Classifications)...)
+	}
+
+	if err := store.ReplaceRaceClassifications(ctx, db, records); err != nil {
+		return xerrors.Errorf("Failed to replace race classifications: %+w", err)
+	}
+
+	log.Printf("Classified %d races by %d schemes\n", len(races), len(config.Classifications)+1)
+
+	return nil
+}
@@ -7,11 +7,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/fetch"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
 )
 
 func cmdCollect(c *cli.Context) error {
+	ctx := c.Context
+
 	raceTopURL := config.Netkeiba.DatabaseURL
 
 	file, err := os.OpenFile(filepath.Join(config.Path.DataDir, filenameRaceList), os.O_WRONLY|os.O_CREATE, os.FileMode(0666))
@@ -21,9 +24,11 @@ func cmdCollect(c *cli.Context) error {
 	defer file.Close()
 
 	for i := 0; i < determineCollectOffset(c); i++ {
-		time.Sleep(time.Second)
+		if err := sleep(ctx, time.Second); err != nil {
+			return err
+		}
 
-		schedulePages, prevMonthPage, err := findRaceSchedulePages(raceTopURL)
+		schedulePages, prevMonthPage, err := fetch.FindRaceSchedulePages(ctx, raceTopURL)
 		if err != nil {
 			log.Printf("Failed to send request to %s: %s", raceTopURL, err)
 			continue
@@ -32,9 +37,11 @@ func cmdCollect(c *cli.Context) error {
 		var racePages []string
 
 		for i := 0; i < len(schedulePages); i++ {
-			time.Sleep(time.Second)
+			if err := sleep(ctx, time.Second); err != nil {
+				return err
+			}
 
-			p, err := findRacePagesOfOneDay(config.Netkeiba.DatabaseURL, schedulePages[i])
+			p, err := fetch.FindRacePagesOfOneDay(ctx, config.Netkeiba.DatabaseURL, schedulePages[i])
 			if err != nil {
 				log.Printf("Failed to send request to %s: %s", schedulePages[i], err)
 				continue
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
@@ -8,6 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/fetch"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/parse"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/store"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
 )
@@ -15,14 +21,19 @@ import (
 func cmdDump(c *cli.Context) error {
 	dataType := c.String("data-type")
 
-	if dataType == "horse" {
-		return dumpHorseData()
+	switch dataType {
+	case "horse":
+		return dumpHorseData(c.Context, c.Bool("recursive"), c.Int("depth"))
+	case parse.ProfileKindJockey, parse.ProfileKindTrainer, parse.ProfileKindOwner:
+		return dumpProfileData(c.Context, dataType, "result")
+	case parse.ProfileKindBreeder:
+		return dumpProfileData(c.Context, dataType, "horse")
 	}
 
-	return dumpRaceData()
+	return dumpRaceData(c.Context)
 }
 
-func dumpRaceData() error {
+func dumpRaceData(ctx context.Context) error {
 	file, err := os.Open(filepath.Join(config.Path.DataDir, filenameRaceList))
 	if err != nil {
 		return xerrors.Errorf("Failed to open file: %+w", err)
@@ -34,56 +45,265 @@ func dumpRaceData() error {
 		return xerrors.Errorf("Failed to read file: %+w", err)
 	}
 
-	if err := loginToNetkeibaCom(config.Netkeiba.LoginURL, config.Netkeiba.Email, config.Netkeiba.Password); err != nil {
+	if err := fetch.Login(ctx, config.Netkeiba.LoginURL, config.Netkeiba.Email, config.Netkeiba.Password); err != nil {
 		return xerrors.Errorf("Failed to login netkeiba.com: %+w", err)
 	}
 
-	racePages := strings.Split(string(b), "\n")
+	cp := openCheckpoint("dump_race")
+	racePages := cp.resume(strings.Split(strings.TrimSpace(string(b)), "\n"))
+
+	workoutPath := filepath.Join(config.Path.DataDir, "workout")
+
+	if err := os.MkdirAll(workoutPath, os.FileMode(0777)); err != nil {
+		return xerrors.Errorf("Failed to create directory: %+w", err)
+	}
 
 	for i := 0; i < len(racePages); i++ {
-		if err := dumpWebPageAsHTMLFile(config.Path.DataDir, racePages[i]); err != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page := withoutCancel{ctx}
+
+		if err := fetch.DumpWebPageAsHTMLFile(page, config.Path.DataDir, racePages[i]); err != nil {
 			log.Printf("Failed to dump %s: %+v", racePages[i], err)
 			continue
 		}
 
-		time.Sleep(5 * time.Second)
+		// the workout of the race is dumped right away when interrupted
+		sleep(ctx, 5*time.Second)
+
+		if err := fetch.DumpWorkoutPageAsHTMLFile(page, workoutPath, config.Netkeiba.RaceURL, racePages[i]); err != nil {
+			log.Printf("Failed to dump workout of %s: %+v", racePages[i], err)
+		}
+
+		cp.done(racePages[i])
+
+		if err := sleep(ctx, 5*time.Second); err != nil {
+			return err
+		}
 	}
 
+	cp.finish()
+
 	return nil
 }
 
-func dumpHorseData() error {
+func dumpHorseData(ctx context.Context, recursive bool, depth int) error {
 	dbFilePath := filepath.Join(config.Path.DataDir, filenameDatabase)
 
-	db, err := util.openDatabase(dbFilePath)
+	db, err := store.Open(dbFilePath)
 	if err != nil {
 		return xerrors.Errorf("Failed to open database: %+w", err)
 	}
 	defer db.Close()
 
-	rows, err := db.Query("SELECT DISTINCT(horse_id) FROM result ORDER BY horse_id ASC")
+	horseIDs, err := queryIDs(ctx, db, "SELECT DISTINCT(horse_id) FROM result ORDER BY horse_id ASC")
 	if err != nil {
 		return err
 	}
 
 	path := filepath.Join(config.Path.DataDir, "horse")
+	profilePath := filepath.Join(config.Path.DataDir, "horse_profile")
 
-	for rows.Next() {
-		var horseID string
+	for _, p := range []string{path, profilePath} {
+		if err := os.MkdirAll(p, os.FileMode(0777)); err != nil {
+			return xerrors.Errorf("Failed to create directory: %+w", err)
+		}
+	}
 
-		if err := rows.Scan(&horseID); err != nil {
+	cp := openCheckpoint("dump_horse")
+	horseIDs = cp.resume(horseIDs)
+
+	for i := 0; i < len(horseIDs); i++ {
+		if err := ctx.Err(); err != nil {
 			return err
 		}
 
-		url := config.Netkeiba.DatabaseURL + "/horse/ped/" + horseID
+		id, err := parse.ParseHorseID(horseIDs[i])
+		if err != nil {
+			log.Printf("Failed to dump horse %q: %+v", horseIDs[i], err)
+			continue
+		}
+
+		page := withoutCancel{ctx}
+		url := config.Netkeiba.DatabaseURL + id.PedigreePath()
+
+		if err := fetch.DumpWebPageAsHTMLFile(page, path, url); err != nil {
+			log.Printf("Failed to dump %s: %+v", url, err)
+			continue
+		}
+
+		// the profile of the horse is dumped right away when interrupted
+		sleep(ctx, 1*time.Second)
 
-		if err := dumpWebPageAsHTMLFile(path, url); err != nil {
+		url = config.Netkeiba.DatabaseURL + id.Path()
+
+		if err := fetch.DumpWebPageAsHTMLFile(page, profilePath, url); err != nil {
 			log.Printf("Failed to dump %s: %+v", url, err)
 			continue
 		}
 
-		time.Sleep(1 * time.Second)
+		cp.done(horseIDs[i])
+
+		if err := sleep(ctx, 1*time.Second); err != nil {
+			return err
+		}
+	}
+
+	cp.finish()
+
+	if recursive {
+		return dumpAncestorData(ctx, path, depth)
 	}
 
 	return nil
 }
+
+// dumpAncestorData expands the pedigree beyond five generations. It dumps the
+// pedigree pages of the fifth generation ancestors of every dumped page, and
+// repeats it for the newly dumped pages until the founders or the given depth
+// is reached. Zero depth means no limit. Pages already dumped are never sent
+// twice, so the expansion can be resumed by running it again.
+func dumpAncestorData(ctx context.Context, path string, depth int) error {
+	files, err := filepath.Glob(filepath.Join(path, "*.html"))
+	if err != nil {
+		return xerrors.Errorf("Failed to glob HTML files: %+w", err)
+	}
+
+	visited := make(map[string]bool, len(files))
+	queue := make([]string, 0, len(files))
+
+	for i := 0; i < len(files); i++ {
+		id := strings.TrimSuffix(filepath.Base(files[i]), ".html")
+		visited[id] = true
+		queue = append(queue, id)
+	}
+
+	for level := 1; 0 < len(queue) && (depth <= 0 || level <= depth); level++ {
+		var next []string
+
+		for i := 0; i < len(queue); i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			ancestors, err := findUnknownAncestors(filepath.Join(path, queue[i]+".html"))
+			if err != nil {
+				log.Printf("Failed to find ancestors of %s: %s", queue[i], err)
+				continue
+			}
+
+			for j := 0; j < len(ancestors); j++ {
+				if visited[ancestors[j]] {
+					continue
+				}
+				visited[ancestors[j]] = true
+
+				id, err := parse.ParseHorseID(ancestors[j])
+				if err != nil {
+					log.Printf("Failed to dump ancestor %q: %+v", ancestors[j], err)
+					continue
+				}
+
+				url := config.Netkeiba.DatabaseURL + id.PedigreePath()
+
+				if err := fetch.DumpWebPageAsHTMLFile(withoutCancel{ctx}, path, url); err != nil {
+					log.Printf("Failed to dump %s: %+v", url, err)
+					continue
+				}
+
+				next = append(next, ancestors[j])
+
+				if err := sleep(ctx, 1*time.Second); err != nil {
+					return err
+				}
+			}
+		}
+
+		log.Printf("Dumped %d ancestors at depth %d", len(next), level)
+
+		queue = next
+	}
+
+	return nil
+}
+
+func dumpProfileData(ctx context.Context, kind string, table string) error {
+	dbFilePath := filepath.Join(config.Path.DataDir, filenameDatabase)
+
+	db, err := store.Open(dbFilePath)
+	if err != nil {
+		return xerrors.Errorf("Failed to open database: %+w", err)
+	}
+	defer db.Close()
+
+	column := kind + "_id"
+
+	ids, err := queryIDs(ctx, db, fmt.Sprintf("SELECT DISTINCT(%s) FROM %s WHERE %s != '' ORDER BY %s ASC", column, table, column, column))
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(config.Path.DataDir, kind)
+
+	if err := os.MkdirAll(path, os.FileMode(0777)); err != nil {
+		return xerrors.Errorf("Failed to create directory: %+w", err)
+	}
+
+	cp := openCheckpoint("dump_" + kind)
+	ids = cp.resume(ids)
+
+	for i := 0; i < len(ids); i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := parse.ProfilePath(kind, ids[i])
+		if err != nil {
+			log.Printf("Failed to dump %s %q: %+v", kind, ids[i], err)
+			continue
+		}
+
+		url := config.Netkeiba.DatabaseURL + page
+
+		if err := fetch.DumpWebPageAsHTMLFile(withoutCancel{ctx}, path, url); err != nil {
+			log.Printf("Failed to dump %s: %+v", url, err)
+			continue
+		}
+
+		cp.done(ids[i])
+
+		if err := sleep(ctx, 1*time.Second); err != nil {
+			return err
+		}
+	}
+
+	cp.finish()
+
+	return nil
+}
+
+// queryIDs reads all the IDs up front, so that the checkpoint can skip the
+// ones already dumped.
+func queryIDs(ctx context.Context, db *sql.DB, query string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+
+	for rows.Next() {
+		var id string
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/fetch"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/parse"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/store"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+func cmdFetchRace(c *cli.Context) error {
+	id := c.Args().First()
+
+	raceID, err := parse.ParseRaceID(id)
+	if err != nil {
+		return xerrors.Errorf("Invalid race ID: %+w", err)
+	}
+
+	if err := fetch.Login(c.Context, config.Netkeiba.LoginURL, config.Netkeiba.Email, config.Netkeiba.Password); err != nil {
+		return xerrors.Errorf("Failed to login netkeiba.com: %+w", err)
+	}
+
+	dataDir, err := fetchDataDir(c.Bool("dry-run"))
+	if err != nil {
+		return err
+	}
+	if dataDir != config.Path.DataDir {
+		defer os.RemoveAll(dataDir)
+	}
+
+	url := config.Netkeiba.DatabaseURL + raceID.Path()
+
+	if err := fetch.DumpWebPageAsHTMLFile(c.Context, dataDir, url); err != nil {
+		return xerrors.Errorf("Failed to dump %s: %+w", url, err)
+	}
+
+	workoutPath := filepath.Join(dataDir, "workout")
+
+	if err := os.MkdirAll(workoutPath, os.FileMode(0777)); err != nil {
+		return xerrors.Errorf("Failed to create directory: %+w", err)
+	}
+
+	if err := fetch.DumpWorkoutPageAsHTMLFile(c.Context, workoutPath, config.Netkeiba.RaceURL, url); err != nil {
+		log.Printf("Failed to dump workout of %s: %+v", url, err)
+	}
+
+	filename := filepath.Join(dataDir, id+".html")
+	workoutFilename := filepath.Join(workoutPath, id+".html")
+
+	if c.Bool("dry-run") {
+		return printRaceData(filename, workoutFilename)
+	}
+
+	db, err := openFetchDatabase(c.Context)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	warnings, err := importRaceData(c.Context, db, filename)
+	logWarnings(warnings)
+
+	if err != nil {
+		return xerrors.Errorf("Failed to import %s: %+w", filename, err)
+	}
+
+	// workouts are only available for premium accounts
+	if err := importWorkoutData(c.Context, db, workoutFilename); err != nil {
+		log.Printf("Failed to import %s: %s\n", workoutFilename, err)
+	}
+
+	return nil
+}
+
+func cmdFetchHorse(c *cli.Context) error {
+	id := c.Args().First()
+
+	horseID, err := parse.ParseHorseID(id)
+	if err != nil {
+		return xerrors.Errorf("Invalid horse ID: %+w", err)
+	}
+
+	dataDir, err := fetchDataDir(c.Bool("dry-run"))
+	if err != nil {
+		return err
+	}
+	if dataDir != config.Path.DataDir {
+		defer os.RemoveAll(dataDir)
+	}
+
+	pedigreePath := filepath.Join(dataDir, "horse")
+	profilePath := filepath.Join(dataDir, "horse_profile")
+
+	for _, path := range []string{pedigreePath, profilePath} {
+		if err := os.MkdirAll(path, os.FileMode(0777)); err != nil {
+			return xerrors.Errorf("Failed to create directory: %+w", err)
+		}
+	}
+
+	url := config.Netkeiba.DatabaseURL + horseID.PedigreePath()
+
+	if err := fetch.DumpWebPageAsHTMLFile(c.Context, pedigreePath, url); err != nil {
+		return xerrors.Errorf("Failed to dump %s: %+w", url, err)
+	}
+
+	url = config.Netkeiba.DatabaseURL + horseID.Path()
+
+	if err := fetch.DumpWebPageAsHTMLFile(c.Context, profilePath, url); err != nil {
+		return xerrors.Errorf("Failed to dump %s: %+w", url, err)
+	}
+
+	pedigreeFilename := filepath.Join(pedigreePath, id+".html")
+	profileFilename := filepath.Join(profilePath, id+".html")
+
+	if c.Bool("dry-run") {
+		return printHorseData(id, pedigreeFilename, profileFilename)
+	}
+
+	db, err := openFetchDatabase(c.Context)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := importHorseData(c.Context, db, pedigreeFilename); err != nil {
+		return xerrors.Errorf("Failed to import %s: %+w", pedigreeFilename, err)
+	}
+
+	if err := importHorseProfileData(c.Context, db, profileFilename); err != nil {
+		return xerrors.Errorf("Failed to import %s: %+w", profileFilename, err)
+	}
+
+	return nil
+}
+
+// fetchDataDir returns the directory to dump the pages into: the data
+// directory, or a temporary directory for a dry run, which the caller removes.
+func fetchDataDir(dryRun bool) (string, error) {
+	if !dryRun {
+		return config.Path.DataDir, nil
+	}
+
+	dir, err := ioutil.TempDir("", "netkeiba-fetch")
+	if err != nil {
+		return "", xerrors.Errorf("Failed to create temporary directory: %+w", err)
+	}
+
+	return dir, nil
+}
+
+func openFetchDatabase(ctx context.Context) (*sql.DB, error) {
+	dbFilePath := filepath.Join(config.Path.DataDir, filenameDatabase)
+
+	if err := store.Setup(ctx, dbFilePath, false); err != nil {
+		return nil, xerrors.Errorf("Failed to setup database: %+w", err)
+	}
+
+	db, err := store.Open(dbFilePath)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to open database: %+w", err)
+	}
+
+	return db, nil
+}
+
+func printRaceData(filename string, workoutFilename string) error {
+	id, err := parse.ParseRaceID(strings.TrimSuffix(filepath.Base(filename), ".html"))
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	page, err := parse.ReadRacePage(id, file)
+	if err != nil {
+		return err
+	}
+
+	var workouts []*parse.Workout
+
+	// workouts are only available for premium accounts
+	if doc, err := htmlquery.LoadDoc(workoutFilename); err == nil {
+		if workouts, err = parse.BuildWorkoutRecords(id, doc); err != nil {
+			log.Printf("Failed to build workout records: %s\n", err)
+		}
+	}
+
+	return printJSON(map[string]interface{}{
+		"race":            page.Race,
+		"classifications": parse.ClassifyRace(page.Race, config.Classifications),
+		"payouts":         page.Payouts,
+		"results":         page.Results,
+		"workouts":        workouts,
+	})
+}
+
+func printHorseData(id string, pedigreeFilename string, profileFilename string) error {
+	doc, err := htmlquery.LoadDoc(pedigreeFilename)
+	if err != nil {
+		return err
+	}
+
+	horses, err := parse.BuildHorseRecords(id, doc)
+	if err != nil {
+		return err
+	}
+
+	doc, err = htmlquery.LoadDoc(profileFilename)
+	if err != nil {
+		return err
+	}
+
+	profile, err := parse.BuildHorseProfileRecord(id, doc)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(map[string]interface{}{
+		"horses":  horses,
+		"profile": profile,
+	})
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
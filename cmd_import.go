@@ -1,54 +1,53 @@
 package main
 
 import (
-	"io/ioutil"
+	"context"
+	"database/sql"
 	"log"
-	"os"
 	"path/filepath"
+	"sync"
 
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/parse"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/store"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
 )
 
 func cmdImport(c *cli.Context) error {
+	ctx := c.Context
+
 	force := c.Bool("force")
 
 	dbFilePath := filepath.Join(config.Path.DataDir, filenameDatabase)
 
-	if err := setupDatabase(dbFilePath, force); err != nil {
+	if err := store.Setup(ctx, dbFilePath, force); err != nil {
 		return xerrors.Errorf("Failed to setup database: %+w", err)
 	}
 
-	db, err := util.openDatabase(dbFilePath)
+	db, err := store.Open(dbFilePath)
 	if err != nil {
 		return xerrors.Errorf("Failed to open database: %+w", err)
 	}
 	defer db.Close()
 
-	files, err := filepath.Glob(filepath.Join(config.Path.DataDir, "*.html"))
+	report, err := importFiles(ctx, db, config.Path.DataDir, c.Int("jobs"), c.Int("batch"))
 	if err != nil {
-		return xerrors.Errorf("Failed to glob HTML files: %+w", err)
+		return err
 	}
 
-	log.Printf("Importing %d race data ...\n", len(files))
-
-	for i := 0; i < len(files); i++ {
-		if err := importRaceData(db, files[i]); err != nil {
-			log.Printf("Failed to import %s: %s\n", files[i], err)
-		}
+	reportFilePath := c.String("report")
+	if reportFilePath == "" {
+		reportFilePath = filepath.Join(config.Path.DataDir, filenameImportReport)
 	}
 
-	files, err = filepath.Glob(filepath.Join(config.Path.DataDir, "horse", "*.html"))
-	if err != nil {
-		return xerrors.Errorf("Failed to glob HTML files: %+w", err)
+	if err := report.write(reportFilePath); err != nil {
+		return xerrors.Errorf("Failed to write report: %+w", err)
 	}
 
-	log.Printf("Importing %d horse data ...\n", len(files))
+	log.Printf("Imported %d files, skipped %d unchanged files, %d failures and %d warnings, see %s\n", report.Success, report.Skipped, report.Failure, report.Warning, reportFilePath)
 
-	for i := 0; i < len(files); i++ {
-		if err := importHorseData(db, files[i]); err != nil {
-			log.Printf("Failed to import %s: %s\n", files[i], err)
-		}
+	if c.Bool("strict") && (0 < report.Failure || 0 < report.Warning) {
+		return xerrors.Errorf("%d failures and %d warnings in strict mode", report.Failure, report.Warning)
 	}
 
 	log.Println("Succeeded to import data")
@@ -56,39 +55,237 @@ func cmdImport(c *cli.Context) error {
 	return nil
 }
 
-func setupDatabase(dbFilePath string, force bool) error {
-	if force {
-		os.Remove(dbFilePath)
+// importFiles imports the pages in dataDir which are new or changed since
+// they were logged, and returns the report.
+func importFiles(ctx context.Context, db *sql.DB, dataDir string, jobs int, batchSize int) (*importReport, error) {
+	logs, err := store.SelectImportLogs(ctx, db)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to select import logs: %+w", err)
 	}
 
-	if _, err := os.Stat(dbFilePath); err == nil {
-		return nil // already set up
+	p := &importPipeline{
+		db:        db,
+		logs:      logs,
+		report:    newImportReport(),
+		jobs:      jobs,
+		batchSize: batchSize,
 	}
 
-	done := false
+	if p.jobs < 1 {
+		p.jobs = 1
+	}
+	if p.batchSize < 1 {
+		p.batchSize = 1
+	}
 
-	db, err := util.openDatabase(dbFilePath)
-	if err != nil {
-		return err
+	sources := []importSource{
+		{"race", filepath.Join(dataDir, "*.html"), readRaceData},
+		{"workout", filepath.Join(dataDir, "workout", "*.html"), readWorkoutData},
+		{"horse", filepath.Join(dataDir, "horse", "*.html"), readHorseData},
+		{"horse profile", filepath.Join(dataDir, "horse_profile", "*.html"), readHorseProfileData},
+	}
+
+	for _, kind := range []string{parse.ProfileKindJockey, parse.ProfileKindTrainer, parse.ProfileKindOwner, parse.ProfileKindBreeder} {
+		sources = append(sources, importSource{kind, filepath.Join(dataDir, kind, "*.html"), profileDataReader(kind)})
+	}
+
+	for i := 0; i < len(sources); i++ {
+		files, err := filepath.Glob(sources[i].pattern)
+		if err != nil {
+			return nil, xerrors.Errorf("Failed to glob HTML files: %+w", err)
+		}
+
+		log.Printf("Importing %d %s data ...\n", len(files), sources[i].kind)
+
+		if err := p.run(ctx, sources[i].kind, files, sources[i].read); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.report, nil
+}
+
+// importSource is the kind of pages to import, and where they are.
+type importSource struct {
+	kind    string
+	pattern string
+	read    reader
+}
+
+// importPipeline parses the files by jobs goroutines in parallel, and writes
+// them by a single writer, batchSize pages per transaction. SQLite allows
+// only one writer at a time, so that more writers would just wait for the
+// lock. The files unchanged since they were logged in logs are skipped.
+type importPipeline struct {
+	db        *sql.DB
+	logs      map[string]*store.ImportLog
+	report    *importReport
+	jobs      int
+	batchSize int
+}
+
+// importPage is a page parsed by the pipeline. The skipped page has no
+// writer, and has the import log only when the log has to be updated.
+type importPage struct {
+	file     string
+	log      *store.ImportLog
+	skipped  bool
+	write    writer
+	warnings []*parse.Error
+	err      error
+}
+
+func (p *importPipeline) run(ctx context.Context, kind string, files []string, read reader) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	paths := make(chan string)
+	pages := make(chan *importPage, p.jobs)
+
+	go func() {
+		defer close(paths)
+
+		for i := 0; i < len(files); i++ {
+			select {
+			case paths <- files[i]:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < p.jobs; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for file := range paths {
+				select {
+				case pages <- p.read(file, read):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
 	}
 
+	go func() {
+		wg.Wait()
+		close(pages)
+	}()
+
+	return p.write(ctx, kind, pages)
+}
+
+// read parses the file unless it is unchanged. A panic in the parser fails
+// the file instead of the whole import.
+func (p *importPipeline) read(file string, read reader) (page *importPage) {
+	page = &importPage{file: file}
+
 	defer func() {
-		db.Close()
-		if !done {
-			os.Remove(dbFilePath)
+		if v := recover(); v != nil {
+			page.err = parse.WithFile(xerrors.Errorf("panic: %v", v), file)
 		}
 	}()
 
-	b, err := ioutil.ReadFile("./schema.sql")
+	l, changed, err := checkImportLog(p.logs, file)
 	if err != nil {
-		return err
+		page.err = err
+		return page
+	}
+
+	if !changed {
+		page.skipped = true
+
+		// the file was touched, but its content is the same
+		if l != p.logs[l.Path] {
+			page.log = l
+		}
+
+		return page
+	}
+
+	page.log = l
+	page.write, page.warnings, page.err = read(file)
+
+	return page
+}
+
+// write writes the pages in batches. When ctx is canceled, the batch in
+// progress is rolled back and the committed ones are kept.
+func (p *importPipeline) write(ctx context.Context, kind string, pages <-chan *importPage) error {
+	var b *store.Batch
+	n := 0
+
+	defer func() {
+		if b != nil {
+			b.Rollback()
+		}
+	}()
+
+	for page := range pages {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if page.err != nil {
+			p.report.add(kind, page.file, page.warnings, page.err)
+			continue
+		}
+
+		if page.skipped {
+			p.report.Skipped++
+
+			if page.log == nil {
+				continue
+			}
+		}
+
+		if b == nil {
+			var err error
+			if b, err = store.Begin(ctx, p.db); err != nil {
+				return xerrors.Errorf("Failed to begin transaction: %+w", err)
+			}
+		}
+
+		err := b.Do(ctx, func() error {
+			if page.write != nil {
+				if err := page.write(ctx, b); err != nil {
+					return err
+				}
+			}
+
+			return b.InsertImportLog(ctx, page.log)
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if !page.skipped {
+			p.report.add(kind, page.file, page.warnings, err)
+		}
+
+		if n++; p.batchSize <= n {
+			if err := b.Commit(); err != nil {
+				return xerrors.Errorf("Failed to commit transaction: %+w", err)
+			}
+			b, n = nil, 0
+		}
 	}
 
-	if _, err := db.Exec(string(b)); err != nil {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	done = true
+	if b != nil {
+		if err := b.Commit(); err != nil {
+			return xerrors.Errorf("Failed to commit transaction: %+w", err)
+		}
+		b = nil
+	}
 
 	return nil
 }
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/store"
+)
+
+// copyRacePages copies the race pages of the parse tests into a data
+// directory, named by the race ID as they are dumped, and returns the number
+// of the pages. The first page of an ID is copied when there are cases of the
+// same race.
+func copyRacePages(tb testing.TB, dataDir string) int {
+	files, err := filepath.Glob(filepath.Join("netkeiba", "parse", "testdata", "race", "*.html"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	n := 0
+
+	for i := 0; i < len(files); i++ {
+		id := strings.SplitN(filepath.Base(files[i]), "_", 2)[0]
+		id = strings.TrimSuffix(id, ".html")
+
+		dst := filepath.Join(dataDir, id+".html")
+		if _, err := os.Stat(dst); err == nil {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(files[i])
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(dst, b, os.FileMode(0666)); err != nil {
+			tb.Fatal(err)
+		}
+
+		n++
+	}
+
+	return n
+}
+
+func openImportDatabase(tb testing.TB) *sql.DB {
+	dbFilePath := filepath.Join(tb.TempDir(), filenameDatabase)
+
+	if err := store.Setup(context.Background(), dbFilePath, false); err != nil {
+		tb.Fatal(err)
+	}
+
+	db, err := store.Open(dbFilePath)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return db
+}
+
+func TestImportFiles(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	ctx := context.Background()
+
+	dataDir := t.TempDir()
+	n := copyRacePages(t, dataDir)
+
+	db := openImportDatabase(t)
+	defer db.Close()
+
+	report, err := importFiles(ctx, db, dataDir, runtime.NumCPU(), 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Success != n || report.Failure != 0 {
+		t.Fatalf("importFiles() = %d success, %d failures %v, want %d success", report.Success, report.Failure, report.Failures, n)
+	}
+
+	var races int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM race").Scan(&races); err != nil {
+		t.Fatal(err)
+	}
+	if races != n {
+		t.Errorf("race rows = %d, want %d", races, n)
+	}
+
+	report, err = importFiles(ctx, db, dataDir, runtime.NumCPU(), 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Success != 0 || report.Skipped != n {
+		t.Errorf("importFiles() again = %d success, %d skipped, want all %d skipped", report.Success, report.Skipped, n)
+	}
+}
+
+// BenchmarkImportFiles is the whole import of the race pages into a new
+// database, parsed by 1, 2 and 4 goroutines and by one per CPU.
+func BenchmarkImportFiles(b *testing.B) {
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	ctx := context.Background()
+
+	dataDir := b.TempDir()
+	copyRacePages(b, dataDir)
+
+	jobs := []int{1, 2, 4}
+	if n := runtime.NumCPU(); n != 1 && n != 2 && n != 4 {
+		jobs = append(jobs, n)
+	}
+
+	for i := 0; i < len(jobs); i++ {
+		n := jobs[i]
+
+		b.Run(fmt.Sprintf("jobs=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				db := openImportDatabase(b)
+				b.StartTimer()
+
+				if _, err := importFiles(ctx, db, dataDir, n, 500); err != nil {
+					b.Fatal(err)
+				}
+
+				b.StopTimer()
+				db.Close()
+				b.StartTimer()
+			}
+		})
+	}
+}
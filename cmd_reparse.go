@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/parse"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/store"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+// reparseSource is the kind of the pages in a directory under the data
+// directory, and the rows written from the page file of the ID.
+type reparseSource struct {
+	read   reader
+	scopes func(id string, file string) ([]*store.Scope, error)
+}
+
+func reparseSourceOf(path string) (*reparseSource, bool) {
+	switch kind := filepath.Dir(path); kind {
+	case ".":
+		return &reparseSource{readRaceData, func(id string, file string) ([]*store.Scope, error) {
+			return []*store.Scope{
+				{Table: "race", Where: "id = ?", Args: []interface{}{id}},
+				{Table: "race_classification", Where: "race_id = ?", Args: []interface{}{id}},
+				{Table: "payout", Where: "race_id = ?", Args: []interface{}{id}},
+				{Table: "payout_combination", Where: "race_id = ?", Args: []interface{}{id}},
+				{Table: "result", Where: "race_id = ?", Args: []interface{}{id}},
+				{Table: "result_position", Where: "race_id = ?", Args: []interface{}{id}},
+			}, nil
+		}}, true
+	case "workout":
+		return &reparseSource{readWorkoutData, func(id string, file string) ([]*store.Scope, error) {
+			return []*store.Scope{
+				{Table: "workout", Where: "race_id = ?", Args: []interface{}{id}},
+				{Table: "workout_split", Where: "(horse_id, date) IN (SELECT horse_id, date FROM workout WHERE race_id = ?)", Args: []interface{}{id}},
+			}, nil
+		}}, true
+	case "horse":
+		return &reparseSource{readHorseData, horseScopes}, true
+	case "horse_profile":
+		return &reparseSource{readHorseProfileData, func(id string, file string) ([]*store.Scope, error) {
+			return []*store.Scope{
+				{Table: "horse", Where: "id = ?", Args: []interface{}{id}},
+			}, nil
+		}}, true
+	case parse.ProfileKindJockey, parse.ProfileKindTrainer, parse.ProfileKindOwner, parse.ProfileKindBreeder:
+		return &reparseSource{profileDataReader(kind), func(id string, file string) ([]*store.Scope, error) {
+			return []*store.Scope{
+				{Table: kind, Where: "id = ?", Args: []interface{}{id}},
+				{Table: kind + "_stats", Where: kind + "_id = ?", Args: []interface{}{id}},
+			}, nil
+		}}, true
+	}
+
+	return nil, false
+}
+
+// horseScopes returns the rows of the horse and its ancestors, which are
+// written from the pedigree page too.
+func horseScopes(id string, file string) ([]*store.Scope, error) {
+	doc, err := htmlquery.LoadDoc(file)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := parse.BuildHorseRecords(id, doc)
+	if err != nil {
+		return nil, parse.WithFile(err, file)
+	}
+
+	ids := []interface{}{id}
+	seen := map[parse.HorseID]bool{parse.HorseID(id): true}
+
+	for i := 0; i < len(records); i++ {
+		if !seen[records[i].ID] {
+			seen[records[i].ID] = true
+			ids = append(ids, records[i].ID)
+		}
+	}
+
+	return []*store.Scope{
+		{Table: "horse", Where: "id IN (?" + strings.Repeat(", ?", len(ids)-1) + ")", Args: ids},
+	}, nil
+}
+
+// reparseReport is the outcome of the reparse command, with every value
+// changed by the current parser.
+type reparseReport struct {
+	StartedAt     time.Time            `json:"started_at"`
+	FinishedAt    time.Time            `json:"finished_at"`
+	SinceVersion  int                  `json:"since_version"`
+	ParserVersion int                  `json:"parser_version"`
+	Files         int                  `json:"files"`
+	ChangedFiles  int                  `json:"changed_files"`
+	Failures      []*importReportEntry `json:"failures"`
+	Changes       []*reparseChange     `json:"changes"`
+}
+
+type reparseChange struct {
+	File string `json:"file"`
+	*store.Change
+}
+
+func cmdReparse(c *cli.Context) error {
+	ctx := c.Context
+
+	since := c.Int("since-version")
+
+	dbFilePath := filepath.Join(config.Path.DataDir, filenameDatabase)
+
+	if err := store.Setup(ctx, dbFilePath, false); err != nil {
+		return xerrors.Errorf("Failed to setup database: %+w", err)
+	}
+
+	db, err := store.Open(dbFilePath)
+	if err != nil {
+		return xerrors.Errorf("Failed to open database: %+w", err)
+	}
+	defer db.Close()
+
+	logs, err := store.SelectImportLogs(ctx, db)
+	if err != nil {
+		return xerrors.Errorf("Failed to select import logs: %+w", err)
+	}
+
+	var paths []string
+
+	for path, l := range logs {
+		if since <= l.ParserVersion {
+			paths = append(paths, path)
+		}
+	}
+
+	sort.Strings(paths)
+
+	report := &reparseReport{
+		StartedAt:     time.Now(),
+		SinceVersion:  since,
+		ParserVersion: parse.Version,
+		Failures:      []*importReportEntry{},
+		Changes:       []*reparseChange{},
+	}
+
+	log.Printf("Reparsing %d files imported by parser version %d or later ...\n", len(paths), since)
+
+	for i := 0; i < len(paths); i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		src, ok := reparseSourceOf(paths[i])
+		if !ok {
+			log.Printf("Skipped %s: unknown kind of page\n", paths[i])
+			continue
+		}
+
+		file := filepath.Join(config.Path.DataDir, paths[i])
+
+		changes, err := reparseFile(ctx, db, file, src)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		report.Files++
+
+		if err != nil {
+			log.Printf("Failed to reparse %s: %s\n", file, err)
+			report.Failures = append(report.Failures, newImportReportEntry(filepath.Dir(paths[i]), file, err))
+			continue
+		}
+
+		if 0 < len(changes) {
+			report.ChangedFiles++
+		}
+
+		for j := 0; j < len(changes); j++ {
+			report.Changes = append(report.Changes, &reparseChange{File: file, Change: changes[j]})
+		}
+	}
+
+	report.FinishedAt = time.Now()
+
+	reportFilePath := c.String("report")
+	if reportFilePath == "" {
+		reportFilePath = filepath.Join(config.Path.DataDir, filenameReparseReport)
+	}
+
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(reportFilePath, append(b, '\n'), os.FileMode(0666)); err != nil {
+		return xerrors.Errorf("Failed to write report: %+w", err)
+	}
+
+	log.Printf("Reparsed %d files, %d values changed in %d files, %d failures, see %s\n", report.Files, len(report.Changes), report.ChangedFiles, len(report.Failures), reportFilePath)
+
+	return nil
+}
+
+// reparseFile imports the file again, and returns the values changed in the
+// rows written from it.
+func reparseFile(ctx context.Context, db *sql.DB, file string, src *reparseSource) (changes []*store.Change, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = parse.WithFile(xerrors.Errorf("panic: %v", v), file)
+		}
+	}()
+
+	write, _, err := src.read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	l, _, err := checkImportLog(nil, file)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := store.Begin(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer b.Rollback()
+
+	scopes, err := src.scopes(strings.TrimSuffix(filepath.Base(file), ".html"), file)
+	if err != nil {
+		return nil, err
+	}
+
+	before := make([]map[string]store.Row, len(scopes))
+
+	for i := 0; i < len(scopes); i++ {
+		if before[i], err = b.SelectRows(ctx, scopes[i]); err != nil {
+			return nil, xerrors.Errorf("select %s failure: %+w", scopes[i].Table, err)
+		}
+	}
+
+	if err := write(ctx, b); err != nil {
+		return nil, err
+	}
+
+	if err := b.InsertImportLog(ctx, l); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(scopes); i++ {
+		after, err := b.SelectRows(ctx, scopes[i])
+		if err != nil {
+			return nil, xerrors.Errorf("select %s failure: %+w", scopes[i].Table, err)
+		}
+
+		changes = append(changes, store.DiffRows(scopes[i].Table, before[i], after)...)
+	}
+
+	if err := b.Commit(); err != nil {
+		return nil, xerrors.Errorf("commit failure: %+w", err)
+	}
+
+	return changes, nil
+}
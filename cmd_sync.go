@@ -3,17 +3,21 @@ package main
 import (
 	"database/sql"
 	"log"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/fetch"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/store"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
 )
 
 func cmdSync(c *cli.Context) error {
-	db, err := util.openDatabase(filepath.Join(config.Path.DataDir, filenameDatabase))
+	ctx := c.Context
+
+	db, err := store.Open(filepath.Join(config.Path.DataDir, filenameDatabase))
 	if err != nil {
 		return xerrors.Errorf("Failed to open database: %+w", err)
 	}
@@ -21,7 +25,7 @@ func cmdSync(c *cli.Context) error {
 
 	var dateRaw string
 
-	if err := db.QueryRow("SELECT date FROM race ORDER BY date DESC LIMIT 1;").Scan(&dateRaw); err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+	if err := db.QueryRowContext(ctx, "SELECT date FROM race ORDER BY date DESC LIMIT 1;").Scan(&dateRaw); err != nil && !xerrors.Is(err, sql.ErrNoRows) {
 		return xerrors.Errorf("Failed to query row: %+w", err)
 	}
 
@@ -36,9 +40,11 @@ func cmdSync(c *cli.Context) error {
 	var racePages []string
 L:
 	for {
-		time.Sleep(time.Second)
+		if err := sleep(ctx, time.Second); err != nil {
+			return err
+		}
 
-		schedulePages, prevMonthPage, err := findRaceSchedulePages(raceTopURL)
+		schedulePages, prevMonthPage, err := fetch.FindRaceSchedulePages(ctx, raceTopURL)
 		if err != nil {
 			log.Printf("Failed to send request to %s: %s", raceTopURL, err)
 			continue
@@ -50,13 +56,15 @@ L:
 		}
 
 		for i := 0; i < len(schedulePages); i++ {
-			time.Sleep(time.Second)
+			if err := sleep(ctx, time.Second); err != nil {
+				return err
+			}
 
 			if strings.Contains(schedulePages[i], date) {
 				break L
 			}
 
-			p, err := findRacePagesOfOneDay(config.Netkeiba.DatabaseURL, schedulePages[i])
+			p, err := fetch.FindRacePagesOfOneDay(ctx, config.Netkeiba.DatabaseURL, schedulePages[i])
 			if err != nil {
 				log.Printf("Failed to send request to %s: %s", schedulePages[i], err)
 				continue
@@ -73,23 +81,53 @@ L:
 		return nil
 	}
 
-	if err := loginToNetkeibaCom(config.Netkeiba.LoginURL, config.Netkeiba.Email, config.Netkeiba.Password); err != nil {
+	if err := fetch.Login(ctx, config.Netkeiba.LoginURL, config.Netkeiba.Email, config.Netkeiba.Password); err != nil {
 		return err
 	}
 
+	workoutPath := filepath.Join(config.Path.DataDir, "workout")
+
+	if err := os.MkdirAll(workoutPath, os.FileMode(0777)); err != nil {
+		return xerrors.Errorf("Failed to create directory: %+w", err)
+	}
+
 	for i := 0; i < len(racePages); i++ {
-		if err := dumpWebPageAsHTMLFile(config.Path.DataDir, racePages[i]); err != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := fetch.DumpWebPageAsHTMLFile(ctx, config.Path.DataDir, racePages[i]); err != nil {
 			log.Printf("Failed to dump %s: %+v", racePages[i], err)
 			continue
 		}
 
-		filename := filepath.Join(config.Path.DataDir, determineDumpHTMLFilenameFromURL(racePages[i]))
+		filename := filepath.Join(config.Path.DataDir, fetch.DetermineDumpHTMLFilenameFromURL(racePages[i]))
+
+		warnings, err := importRaceData(ctx, db, filename)
+		logWarnings(warnings)
+
+		if err != nil {
+			log.Printf("Failed to import %s: %s\n", filename, err)
+		}
+
+		if err := sleep(ctx, 5*time.Second); err != nil {
+			return err
+		}
+
+		if err := fetch.DumpWorkoutPageAsHTMLFile(ctx, workoutPath, config.Netkeiba.RaceURL, racePages[i]); err != nil {
+			log.Printf("Failed to dump workout of %s: %+v", racePages[i], err)
+			continue
+		}
+
+		filename = filepath.Join(workoutPath, fetch.DetermineDumpHTMLFilenameFromURL(racePages[i]))
 
-		if err := importRaceData(db, filename); err != nil {
+		if err := importWorkoutData(ctx, db, filename); err != nil {
 			log.Printf("Failed to import %s: %s\n", filename, err)
 		}
 
-		time.Sleep(5 * time.Second)
+		if err := sleep(ctx, 5*time.Second); err != nil {
+			return err
+		}
 	}
 
 	return nil
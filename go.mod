@@ -1,18 +1,37 @@
 module github.com/riverside-jp/go-netkeiba-scraper
 
-go 1.16
+go 1.18
 
 require (
-	github.com/agext/levenshtein v1.2.3 // indirect
 	github.com/antchfx/htmlquery v1.2.3
 	github.com/antchfx/xpath v1.1.8
 	github.com/gocolly/colly/v2 v2.1.0
 	github.com/hashicorp/hcl/v2 v2.10.0
 	github.com/mattn/go-sqlite3 v1.14.7
-	github.com/mitchellh/go-wordwrap v1.0.1 // indirect
 	github.com/urfave/cli/v2 v2.3.0
-	github.com/zclconf/go-cty v1.8.3 // indirect
 	golang.org/x/net v0.0.0-20200602114024-627f9648deb9
 	golang.org/x/text v0.3.6
 	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543
 )
+
+require (
+	github.com/PuerkitoBio/goquery v1.5.1 // indirect
+	github.com/agext/levenshtein v1.2.3 // indirect
+	github.com/andybalholm/cascadia v1.2.0 // indirect
+	github.com/antchfx/xmlquery v1.2.4 // indirect
+	github.com/apparentlymart/go-textseg/v13 v13.0.0 // indirect
+	github.com/cpuguy83/go-md2man/v2 v2.0.0-20190314233015-f79a8a8ca69d // indirect
+	github.com/gobwas/glob v0.2.3 // indirect
+	github.com/golang/groupcache v0.0.0-20200121045136-8c9f03a8e57e // indirect
+	github.com/golang/protobuf v1.4.2 // indirect
+	github.com/google/go-cmp v0.4.0 // indirect
+	github.com/kennygrant/sanitize v1.2.4 // indirect
+	github.com/mitchellh/go-wordwrap v1.0.1 // indirect
+	github.com/russross/blackfriday/v2 v2.0.1 // indirect
+	github.com/saintfish/chardet v0.0.0-20120816061221-3af4cd4741ca // indirect
+	github.com/shurcooL/sanitized_anchor_name v1.0.0 // indirect
+	github.com/temoto/robotstxt v1.1.1 // indirect
+	github.com/zclconf/go-cty v1.8.3 // indirect
+	google.golang.org/appengine v1.6.6 // indirect
+	google.golang.org/protobuf v1.24.0 // indirect
+)
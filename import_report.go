@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/parse"
+	"golang.org/x/xerrors"
+)
+
+// importReport is the outcome of the import command, written as JSON so that
+// it can be checked by other tools.
+type importReport struct {
+	StartedAt  time.Time            `json:"started_at"`
+	FinishedAt time.Time            `json:"finished_at"`
+	Success    int                  `json:"success"`
+	Skipped    int                  `json:"skipped"`
+	Failure    int                  `json:"failure"`
+	Warning    int                  `json:"warning"`
+	Failures   []*importReportEntry `json:"failures"`
+	Warnings   []*importReportEntry `json:"warnings"`
+}
+
+// importReportEntry is a failure or a warning of a file.
+type importReportEntry struct {
+	Kind   string       `json:"kind"`
+	File   string       `json:"file"`
+	RaceID parse.RaceID `json:"race_id,omitempty"`
+	Table  string       `json:"table,omitempty"`
+	Row    int          `json:"row,omitempty"`
+	Field  string       `json:"field,omitempty"`
+	Error  string       `json:"error"`
+}
+
+func newImportReport() *importReport {
+	return &importReport{
+		StartedAt: time.Now(),
+		Failures:  []*importReportEntry{},
+		Warnings:  []*importReportEntry{},
+	}
+}
+
+// add records the outcome of the file.
+func (r *importReport) add(kind string, file string, warnings []*parse.Error, err error) {
+	for i := 0; i < len(warnings); i++ {
+		log.Printf("Warning: %s\n", warnings[i])
+		r.Warnings = append(r.Warnings, newImportReportEntry(kind, file, warnings[i]))
+	}
+	r.Warning += len(warnings)
+
+	if err != nil {
+		log.Printf("Failed to import %s: %s\n", file, err)
+		r.Failures = append(r.Failures, newImportReportEntry(kind, file, err))
+		r.Failure++
+		return
+	}
+
+	r.Success++
+}
+
+func newImportReportEntry(kind string, file string, err error) *importReportEntry {
+	entry := &importReportEntry{Kind: kind, File: file, Error: err.Error()}
+
+	var e *parse.Error
+	if xerrors.As(err, &e) {
+		entry.RaceID = e.RaceID
+		entry.Table = e.Table
+		entry.Row = e.Row
+		entry.Field = e.Field
+		entry.Error = e.Error()
+	}
+
+	return entry
+}
+
+func (r *importReport) write(filename string) error {
+	r.FinishedAt = time.Now()
+
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, append(b, '\n'), os.FileMode(0666))
+}
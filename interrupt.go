@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// sleep pauses the current goroutine for d, or until ctx is canceled.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// withoutCancel is a context which has the values of the parent, but is not
+// canceled with it, like context.WithoutCancel of Go 1.21. The requests of the
+// page in progress are sent with it, so that Ctrl-C finishes the page. The
+// requests still time out.
+type withoutCancel struct {
+	context.Context
+}
+
+func (withoutCancel) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (withoutCancel) Done() <-chan struct{}       { return nil }
+func (withoutCancel) Err() error                  { return nil }
+
+// checkpoint records the last item processed by a long running command, so
+// that the command resumes after it when run again after interruption.
+type checkpoint struct {
+	path string
+	last string
+}
+
+func openCheckpoint(name string) *checkpoint {
+	c := &checkpoint{path: filepath.Join(config.Path.DataDir, name+".checkpoint")}
+
+	if b, err := ioutil.ReadFile(c.path); err == nil {
+		c.last = strings.TrimSpace(string(b))
+	}
+
+	return c
+}
+
+// resume returns the items following the last processed one.
+func (c *checkpoint) resume(items []string) []string {
+	if c.last == "" {
+		return items
+	}
+
+	for i := 0; i < len(items); i++ {
+		if items[i] == c.last {
+			log.Printf("Resuming after %s", c.last)
+			return items[i+1:]
+		}
+	}
+
+	return items
+}
+
+func (c *checkpoint) done(item string) {
+	if err := ioutil.WriteFile(c.path, []byte(item), os.FileMode(0666)); err != nil {
+		log.Printf("Failed to save checkpoint: %s", err)
+	}
+}
+
+// finish removes the checkpoint once all the items are processed.
+func (c *checkpoint) finish() {
+	os.Remove(c.path)
+}
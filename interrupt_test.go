@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithoutCancel(t *testing.T) {
+	type key struct{}
+
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	ctx := withoutCancel{parent}
+
+	cancel()
+
+	if ctx.Err() != nil || ctx.Done() != nil {
+		t.Errorf("canceled with the parent: %v", ctx.Err())
+	}
+	if v := ctx.Value(key{}); v != "value" {
+		t.Errorf("value = %v, want the value of the parent", v)
+	}
+}
@@ -1,16 +1,27 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
 
 	"github.com/hashicorp/hcl/v2/hclsimple"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/fetch"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/parse"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/store"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
 )
 
 const (
-	filenameRaceList = "race_list.txt"
-	filenameDatabase = "race.db"
+	filenameRaceList      = "race_list.txt"
+	filenameDatabase      = "race.db"
+	filenameImportReport  = "import_report.json"
+	filenameReparseReport = "reparse_report.json"
 )
 
 var config Config
@@ -18,26 +29,50 @@ var config Config
 type Config struct {
 	Netkeiba NetkeibaConfig `hcl:"netkeiba,block"`
 	Path     PathConfig     `hcl:"path,block"`
+
+	// Classifications are the classification schemes computed next to the
+	// default one into the race_classification table.
+	Classifications []*parse.ClassificationScheme `hcl:"classification,block"`
 }
 
 type NetkeibaConfig struct {
 	DatabaseURL string `hcl:"db_url"`
+	RaceURL     string `hcl:"race_url,optional"`
 	LoginURL    string `hcl:"login_url"`
 	Email       string `hcl:"email"`
 	Password    string `hcl:"password"`
+	Timeout     int    `hcl:"timeout,optional"`
 }
 
 type PathConfig struct {
 	DataDir string `hcl:"data_dir"`
 }
 
-func init() {
+// loadConfig loads config.hcl. It is called by main rather than init, so that
+// the tests of the commands run without the configuration.
+func loadConfig() {
 	if err := hclsimple.DecodeFile("config.hcl", nil, &config); err != nil {
 		log.Fatalf("Failed to load configuration: %s", err)
 	}
+
+	if err := parse.CheckClassificationSchemes(config.Classifications); err != nil {
+		log.Fatalf("Failed to load configuration: %s", err)
+	}
+
+	if config.Netkeiba.RaceURL == "" {
+		config.Netkeiba.RaceURL = "https://race.netkeiba.com"
+	}
+
+	store.RacePageDir = config.Path.DataDir
+
+	if 0 < config.Netkeiba.Timeout {
+		fetch.Timeout = time.Duration(config.Netkeiba.Timeout) * time.Second
+	}
 }
 
 func main() {
+	loadConfig()
+
 	app := &cli.App{
 		Usage: "scraping tool for data extraction from netkeiba.com",
 		Commands: []*cli.Command{
@@ -60,7 +95,16 @@ func main() {
 					&cli.StringFlag{
 						Name:    "data-type",
 						Aliases: []string{"d"},
-						Usage:   "Specify the type of data to be collected (Default: race and result data)",
+						Usage:   "Specify the type of data to be collected: horse, jockey, trainer, owner or breeder (Default: race and result data)",
+					},
+					&cli.BoolFlag{
+						Name:    "recursive",
+						Aliases: []string{"r"},
+						Usage:   "Expand the pedigree of horses beyond five generations",
+					},
+					&cli.IntFlag{
+						Name:  "depth",
+						Usage: "Number of pedigree pages to trace back with --recursive (Default: until founders)",
 					},
 				},
 				Action: cmdDump,
@@ -74,9 +118,79 @@ func main() {
 						Aliases: []string{"f"},
 						Usage:   "Truncate the database if it already exists, and import the data",
 					},
+					&cli.IntFlag{
+						Name:    "jobs",
+						Aliases: []string{"j"},
+						Value:   runtime.NumCPU(),
+						Usage:   "Number of files parsed in parallel",
+					},
+					&cli.IntFlag{
+						Name:  "batch",
+						Value: 500,
+						Usage: "Number of files written per transaction",
+					},
+					&cli.StringFlag{
+						Name:  "report",
+						Usage: "Write the import report as JSON to the file (default: import_report.json in the data directory)",
+					},
+					&cli.BoolFlag{
+						Name:  "strict",
+						Usage: "Exit with non-zero status if any file fails or has warnings",
+					},
 				},
 				Action: cmdImport,
 			},
+			{
+				Name:  "reparse",
+				Usage: "Import the files imported by the older parser again, and report the changed values",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:     "since-version",
+						Required: true,
+						Usage:    "Reparse the files imported by this parser version or later",
+					},
+					&cli.StringFlag{
+						Name:  "report",
+						Usage: "Write the reparse report as JSON to the file (default: reparse_report.json in the data directory)",
+					},
+				},
+				Action: cmdReparse,
+			},
+			{
+				Name:   "classify",
+				Usage:  "Classify the imported races again by the classification schemes of the configuration",
+				Action: cmdClassify,
+			},
+			{
+				Name:  "fetch",
+				Usage: "Fetch a single race or horse from netkeiba.com and import it into database",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "race",
+						Usage:     "Fetch a race, its results, payouts and workouts",
+						ArgsUsage: "<race_id>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "dry-run",
+								Usage: "Print the parsed records as JSON instead of importing them; the pages are downloaded into a temporary directory and removed, and nothing is written into the data directory",
+							},
+						},
+						Action: cmdFetchRace,
+					},
+					{
+						Name:      "horse",
+						Usage:     "Fetch a horse, its pedigree and profile",
+						ArgsUsage: "<horse_id>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "dry-run",
+								Usage: "Print the parsed records as JSON instead of importing them; the pages are downloaded into a temporary directory and removed, and nothing is written into the data directory",
+							},
+						},
+						Action: cmdFetchHorse,
+					},
+				},
+			},
 			{
 				Name:   "sync",
 				Usage:  "Sync local data with netkeiba.com",
@@ -85,7 +199,18 @@ func main() {
 		},
 	}
 
-	err := app.Run(os.Args)
+	// on SIGINT or SIGTERM the running command stops after finishing the page
+	// it is dumping, or after rolling back the transaction in progress, and it
+	// can be resumed from the checkpoint
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := app.RunContext(ctx, os.Args)
+	if xerrors.Is(err, context.Canceled) {
+		stop()
+		log.Println("Interrupted: run the command again to resume")
+		os.Exit(130)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
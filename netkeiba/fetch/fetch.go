@@ -0,0 +1,227 @@
+// Package fetch downloads the pages of netkeiba.com.
+package fetch
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/parse"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+	"golang.org/x/xerrors"
+)
+
+// Timeout is the time limit for each request sent to netkeiba.com.
+var Timeout = 30 * time.Second
+
+// FindRaceSchedulePages returns the schedule pages of the month shown on the
+// race top page, and the path of the previous month.
+func FindRaceSchedulePages(ctx context.Context, url string) (pages []string, prevMonthPage string, err error) {
+	c := newCollector(ctx)
+
+	c.OnHTML("div.race_calendar table a", func(e *colly.HTMLElement) {
+		pages = append(pages, e.Attr("href"))
+	})
+
+	c.OnHTML("div.race_calendar .rev a:last-child", func(e *colly.HTMLElement) {
+		prevMonthPage = e.Attr("href")
+	})
+
+	c.OnRequest(func(request *colly.Request) {
+		log.Println("Sending request to " + url)
+	})
+
+	if err := c.Visit(url + "/?pid=race_top"); err != nil {
+		return nil, "", err
+	}
+
+	return pages, prevMonthPage, nil
+}
+
+// FindRacePagesOfOneDay returns the URLs of the races on the schedule page.
+func FindRacePagesOfOneDay(ctx context.Context, baseURL string, path string) ([]string, error) {
+	var races []string
+
+	c := newCollector(ctx)
+
+	c.OnHTML("dl.race_top_data_info dd > a", func(e *colly.HTMLElement) {
+		races = append(races, baseURL+e.Attr("href"))
+	})
+
+	c.OnRequest(func(request *colly.Request) {
+		log.Println("Sending request to " + baseURL + path)
+	})
+
+	if err := c.Visit(baseURL + path); err != nil {
+		return nil, err
+	}
+
+	return races, nil
+}
+
+func newCollector(ctx context.Context) *colly.Collector {
+	c := colly.NewCollector()
+
+	c.SetRequestTimeout(Timeout)
+	c.WithTransport(&contextTransport{ctx: ctx, base: http.DefaultTransport})
+
+	return c
+}
+
+// contextTransport binds the requests sent by colly, which knows nothing
+// about context, to ctx.
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
+
+// Login logs in to netkeiba.com. The session is kept by the cookie jar of
+// http.DefaultClient, so that the following requests are sent as the
+// account.
+func Login(ctx context.Context, loginURL string, id string, password string) error {
+	log.Println("Trying to login to netkeiba.com, login_id is " + id)
+
+	jar, err := cookiejar.New(&cookiejar.Options{})
+	if err != nil {
+		return err
+	}
+
+	http.DefaultClient.Jar = jar
+
+	http.DefaultClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	values := url.Values{}
+	values.Set("login_id", id)
+	values.Set("pswd", password)
+	values.Set("pid", "login")
+	values.Set("action", "auth")
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, loginURL, strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	log.Println("Sending request to " + loginURL)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		return xerrors.Errorf("login failure, status code is %d", resp.StatusCode)
+	}
+
+	log.Println("Succeeded to login")
+
+	return nil
+}
+
+// DumpWebPageAsHTMLFile saves the page into dumpDir.
+func DumpWebPageAsHTMLFile(ctx context.Context, dumpDir string, url string) error {
+	return DumpWebPageAsNamedHTMLFile(ctx, filepath.Join(dumpDir, DetermineDumpHTMLFilenameFromURL(url)), url)
+}
+
+// DumpWorkoutPageAsHTMLFile dumps the workout page of the race. The page is
+// only available for premium accounts.
+func DumpWorkoutPageAsHTMLFile(ctx context.Context, dumpDir string, baseURL string, raceURL string) error {
+	filename := DetermineDumpHTMLFilenameFromURL(raceURL)
+
+	id, err := parse.ParseRaceID(strings.TrimSuffix(filename, ".html"))
+	if err != nil {
+		return err
+	}
+
+	url := baseURL + id.WorkoutPath()
+
+	return DumpWebPageAsNamedHTMLFile(ctx, filepath.Join(dumpDir, filename), url)
+}
+
+// DumpWebPageAsNamedHTMLFile saves the page as the file converted to UTF-8.
+// The file is written to a temporary file first and renamed, so that it is
+// never left half-written.
+func DumpWebPageAsNamedHTMLFile(ctx context.Context, filename string, url string) error {
+	b, err := Get(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	tmp := filename + ".tmp"
+
+	if err := ioutil.WriteFile(tmp, b, os.FileMode(0666)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, filename); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	log.Printf("Dumped %s to %s", url, filename)
+
+	return nil
+}
+
+// Get returns the page converted to UTF-8. It sends the cookies of the
+// session started by Login.
+func Get(ctx context.Context, url string) ([]byte, error) {
+	log.Println("Sending request to " + url)
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("request failure, status code is %d", resp.StatusCode)
+	}
+
+	// EUC-JP -> UTF-8
+	r := transform.NewReader(resp.Body, japanese.EUCJP.NewDecoder())
+
+	return ioutil.ReadAll(r)
+}
+
+// DetermineDumpHTMLFilenameFromURL returns the name of the file the page is
+// dumped to, that is the last segment of the URL.
+func DetermineDumpHTMLFilenameFromURL(url string) string {
+	// url looks like "https://db.netkeiba.com/race/202105020305/"
+	s := strings.Split(strings.TrimRight(url, "/"), "/")
+
+	return s[len(s)-1] + ".html"
+}
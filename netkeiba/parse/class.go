@@ -0,0 +1,89 @@
+package parse
+
+import (
+	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	ClassNewcomer = "新馬"
+	ClassMaiden   = "未勝利"
+	Class1Win     = "1勝クラス"
+	Class2Win     = "2勝クラス"
+	Class3Win     = "3勝クラス"
+	ClassOpen     = "オープン"
+)
+
+// eastAsianAgeUntil is the date until which the ages were counted in the
+// East Asian way (数え年), i.e. a year older than today.
+const eastAsianAgeUntil = "2001-01-01"
+
+var (
+	winClassPattern   = regexp.MustCompile(`([123])勝クラス`)
+	prizeClassPattern = regexp.MustCompile(`(\d+)万下`)
+	agePattern        = regexp.MustCompile(`(\d+)歳(以上)?`)
+)
+
+// normalizeClass maps the class of the classification of any era to the
+// class since 2019, when 500万下, 1000万下 and 1600万下 were renamed to 1勝クラス,
+// 2勝クラス and 3勝クラス. The older classes named after the prize money, such
+// as 400万下, 900万下 and 1500万下, are mapped by the amount.
+func normalizeClass(classification string) sql.NullString {
+	var class sql.NullString
+
+	if m := winClassPattern.FindStringSubmatch(classification); m != nil {
+		class.Scan(m[0])
+		return class
+	}
+
+	if m := prizeClassPattern.FindStringSubmatch(classification); m != nil {
+		prize, _ := strconv.Atoi(m[1])
+		switch {
+		case prize <= 500:
+			class.Scan(Class1Win)
+		case prize <= 1000:
+			class.Scan(Class2Win)
+		default:
+			class.Scan(Class3Win)
+		}
+		return class
+	}
+
+	switch {
+	case strings.Contains(classification, "新馬"), strings.Contains(classification, "未出走"):
+		class.Scan(ClassNewcomer)
+	case strings.Contains(classification, "未勝利"):
+		class.Scan(ClassMaiden)
+	case strings.Contains(classification, "オープン"):
+		class.Scan(ClassOpen)
+	}
+
+	return class
+}
+
+// normalizeAgeCondition maps the age condition of the classification, such
+// as "3歳以上", to the age counted since 2001.
+func normalizeAgeCondition(classification string, date string) sql.NullString {
+	var condition sql.NullString
+
+	m := agePattern.FindStringSubmatch(classification)
+	if m == nil {
+		return condition
+	}
+
+	age, _ := strconv.Atoi(m[1])
+	condition.Scan(strconv.Itoa(normalizeAge(age, date)) + "歳" + m[2])
+
+	return condition
+}
+
+// normalizeAge maps the age of the horse in the race on the date to the age
+// counted since 2001.
+func normalizeAge(age int, date string) int {
+	if date < eastAsianAgeUntil && 0 < age {
+		return age - 1
+	}
+	return age
+}
@@ -0,0 +1,39 @@
+package parse
+
+import "testing"
+
+func TestNormalizeClassification(t *testing.T) {
+	tests := []struct {
+		classification string
+		date           string
+		class          string
+		ageCondition   string
+	}{
+		{classification: "2歳新馬", date: "2021-06-05", class: ClassNewcomer, ageCondition: "2歳"},
+		{classification: "3歳新馬", date: "1999-10-02", class: ClassNewcomer, ageCondition: "2歳"},
+		{classification: "4歳未出走", date: "1996-01-06", class: ClassNewcomer, ageCondition: "3歳"},
+		{classification: "3歳未勝利", date: "2021-04-10", class: ClassMaiden, ageCondition: "3歳"},
+		{classification: "3歳以上1勝クラス", date: "2021-10-10", class: Class1Win, ageCondition: "3歳以上"},
+		{classification: "3歳以上500万下", date: "2018-12-28", class: Class1Win, ageCondition: "3歳以上"},
+		{classification: "4歳以上400万下", date: "1997-06-01", class: Class1Win, ageCondition: "3歳以上"},
+		{classification: "4歳以上2勝クラス", date: "2021-01-05", class: Class2Win, ageCondition: "4歳以上"},
+		{classification: "4歳以上1000万下", date: "2018-01-06", class: Class2Win, ageCondition: "4歳以上"},
+		{classification: "5歳以上900万下", date: "1999-02-13", class: Class2Win, ageCondition: "4歳以上"},
+		{classification: "3歳以上3勝クラス", date: "2019-06-01", class: Class3Win, ageCondition: "3歳以上"},
+		{classification: "3歳以上1600万下", date: "2018-06-02", class: Class3Win, ageCondition: "3歳以上"},
+		{classification: "5歳以上1500万下", date: "1999-05-02", class: Class3Win, ageCondition: "4歳以上"},
+		{classification: "3歳以上オープン", date: "2021-06-13", class: ClassOpen, ageCondition: "3歳以上"},
+		{classification: "4歳オープン", date: "2000-05-28", class: ClassOpen, ageCondition: "3歳"},
+		{classification: "障害3歳以上オープン", date: "2021-12-25", class: ClassOpen, ageCondition: "3歳以上"},
+		{classification: "サラ系", date: "2021-01-01"},
+	}
+
+	for _, tt := range tests {
+		if class := normalizeClass(tt.classification); class.String != tt.class {
+			t.Errorf("normalizeClass(%q) = %q, want %q", tt.classification, class.String, tt.class)
+		}
+		if condition := normalizeAgeCondition(tt.classification, tt.date); condition.String != tt.ageCondition {
+			t.Errorf("normalizeAgeCondition(%q, %q) = %q, want %q", tt.classification, tt.date, condition.String, tt.ageCondition)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package parse
+
+import (
+	"golang.org/x/xerrors"
+)
+
+// DefaultClassificationScheme is the name of the scheme of the
+// ClassificationCode, which is always computed.
+const DefaultClassificationScheme = "default"
+
+// ClassificationScheme classifies the races into codes. The code is that of
+// the first rule the race matches, or else the codes of the first value the
+// race matches in each dimension joined in order. The schemes are decoded
+// from the classification blocks of config.hcl.
+type ClassificationScheme struct {
+	Name       string                     `hcl:"name,label"`
+	Rules      []*ClassificationRule      `hcl:"rule,block"`
+	Dimensions []*ClassificationDimension `hcl:"dimension,block"`
+}
+
+// ClassificationDimension is a part of the code such as the surface or the
+// distance band.
+type ClassificationDimension struct {
+	Name   string                `hcl:"name,label"`
+	Values []*ClassificationRule `hcl:"value,block"`
+}
+
+// ClassificationRule is the code of the races which match all of its
+// conditions. An empty list or a zero distance matches any race.
+type ClassificationRule struct {
+	Code          string   `hcl:"code,label"`
+	Surfaces      []string `hcl:"surfaces,optional"`
+	Courses       []string `hcl:"courses,optional"`
+	Classes       []string `hcl:"classes,optional"`
+	AgeConditions []string `hcl:"age_conditions,optional"`
+	MinDistance   int      `hcl:"min_distance,optional"`
+	MaxDistance   int      `hcl:"max_distance,optional"`
+}
+
+// RaceClassification is a row of the race_classification table.
+type RaceClassification struct {
+	RaceID RaceID `json:"race_id"`
+	Scheme string `json:"scheme"`
+	Code   string `json:"code"`
+}
+
+// defaultClassificationScheme has the codes from TS0 to DL3 by the surface,
+// the distance band and the class tier, and S for the steeplechases. The
+// tiers are 0 for the maiden races, 1 for the races of the 2 and 3 year olds,
+// 2 for the races of the older horses and 3 for the 3 win class and open
+// races. The turf races longer than 2700m are TE, and the dirt races longer
+// than 2100m are DL.
+var defaultClassificationScheme = &ClassificationScheme{
+	Name: DefaultClassificationScheme,
+	Rules: []*ClassificationRule{
+		{Code: "S", Surfaces: []string{"障"}},
+	},
+	Dimensions: []*ClassificationDimension{
+		{
+			Name: "surface",
+			Values: []*ClassificationRule{
+				{Code: "T", Surfaces: []string{"芝"}},
+				{Code: "D"},
+			},
+		},
+		{
+			Name: "distance",
+			Values: []*ClassificationRule{
+				{Code: "S", MaxDistance: 1300},
+				{Code: "M", MaxDistance: 1899},
+				{Code: "I", MaxDistance: 2100},
+				{Code: "L", Surfaces: []string{"芝"}, MaxDistance: 2700},
+				{Code: "E", Surfaces: []string{"芝"}},
+				{Code: "L"},
+			},
+		},
+		{
+			Name: "tier",
+			Values: []*ClassificationRule{
+				{Code: "3", Classes: []string{Class3Win, ClassOpen}},
+				{Code: "2", AgeConditions: []string{"3歳以上", "4歳以上"}},
+				{Code: "0", Classes: []string{ClassNewcomer, ClassMaiden}},
+				{Code: "1"},
+			},
+		},
+	},
+}
+
+// CheckClassificationSchemes reports the schemes which can not be stored
+// side by side with the default scheme.
+func CheckClassificationSchemes(schemes []*ClassificationScheme) error {
+	names := map[string]bool{DefaultClassificationScheme: true}
+
+	for i := 0; i < len(schemes); i++ {
+		if names[schemes[i].Name] {
+			return xerrors.Errorf("duplicate classification scheme %q", schemes[i].Name)
+		}
+		names[schemes[i].Name] = true
+
+		if len(schemes[i].Rules) == 0 && len(schemes[i].Dimensions) == 0 {
+			return xerrors.Errorf("classification scheme %q has no rules or dimensions", schemes[i].Name)
+		}
+	}
+
+	return nil
+}
+
+// Classify returns the code of the race, or an empty string if the race
+// matches no rule and no value of a dimension.
+func (s *ClassificationScheme) Classify(race *Race) string {
+	for i := 0; i < len(s.Rules); i++ {
+		if s.Rules[i].match(race) {
+			return s.Rules[i].Code
+		}
+	}
+
+	if len(s.Dimensions) == 0 {
+		return ""
+	}
+
+	code := ""
+
+	for i := 0; i < len(s.Dimensions); i++ {
+		value := s.Dimensions[i].classify(race)
+		if value == nil {
+			return ""
+		}
+		code += value.Code
+	}
+
+	return code
+}
+
+func (d *ClassificationDimension) classify(race *Race) *ClassificationRule {
+	for i := 0; i < len(d.Values); i++ {
+		if d.Values[i].match(race) {
+			return d.Values[i]
+		}
+	}
+
+	return nil
+}
+
+func (r *ClassificationRule) match(race *Race) bool {
+	if 0 < r.MinDistance && race.Distance < r.MinDistance {
+		return false
+	}
+
+	if 0 < r.MaxDistance && r.MaxDistance < race.Distance {
+		return false
+	}
+
+	return matchAny(r.Surfaces, race.Surface) &&
+		matchAny(r.Courses, race.Course) &&
+		matchAny(r.Classes, race.Class.String) &&
+		matchAny(r.AgeConditions, race.AgeCondition.String)
+}
+
+// matchAny returns whether s is one of the values, or true if there are no
+// values.
+func matchAny(values []string, s string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for i := 0; i < len(values); i++ {
+		if values[i] == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClassifyRace classifies the race by the default scheme and the schemes.
+// The races which a scheme does not classify have no row of the scheme.
+func ClassifyRace(race *Race, schemes []*ClassificationScheme) []*RaceClassification {
+	records := []*RaceClassification{}
+
+	if race.ClassificationCode != "" {
+		records = append(records, &RaceClassification{RaceID: race.ID, Scheme: DefaultClassificationScheme, Code: race.ClassificationCode})
+	}
+
+	for i := 0; i < len(schemes); i++ {
+		if code := schemes[i].Classify(race); code != "" {
+			records = append(records, &RaceClassification{RaceID: race.ID, Scheme: schemes[i].Name, Code: code})
+		}
+	}
+
+	return records
+}
+
+// DetermineClassificationCode determines the code of the race by the default
+// scheme. The class and the age condition are normalized, so that the races
+// of any era fall into the same codes.
+func DetermineClassificationCode(race *Race) string {
+	return defaultClassificationScheme.Classify(race)
+}
@@ -0,0 +1,153 @@
+package parse
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclsimple"
+)
+
+func newClassifiedRace(course string, surface string, distance int, class string, ageCondition string) *Race {
+	return &Race{
+		ID:           202105021211,
+		Course:       course,
+		Surface:      surface,
+		Distance:     distance,
+		Class:        sql.NullString{String: class, Valid: class != ""},
+		AgeCondition: sql.NullString{String: ageCondition, Valid: ageCondition != ""},
+	}
+}
+
+func TestDetermineClassificationCode(t *testing.T) {
+	tests := []struct {
+		race *Race
+		code string
+	}{
+		{race: newClassifiedRace("東京", "芝", 1200, ClassNewcomer, "2歳"), code: "TS0"},
+		{race: newClassifiedRace("東京", "芝", 1300, Class1Win, "3歳"), code: "TS1"},
+		{race: newClassifiedRace("東京", "芝", 1301, Class1Win, "3歳以上"), code: "TM2"},
+		{race: newClassifiedRace("東京", "芝", 1899, Class2Win, "4歳以上"), code: "TM2"},
+		{race: newClassifiedRace("東京", "芝", 2000, ClassMaiden, "3歳"), code: "TI0"},
+		{race: newClassifiedRace("東京", "芝", 2400, ClassOpen, "3歳"), code: "TL3"},
+		{race: newClassifiedRace("中山", "芝", 3600, ClassOpen, "3歳以上"), code: "TE3"},
+		{race: newClassifiedRace("中山", "ダ", 1200, ClassMaiden, "3歳"), code: "DS0"},
+		{race: newClassifiedRace("中山", "ダ", 1800, Class3Win, "3歳以上"), code: "DM3"},
+		{race: newClassifiedRace("中山", "ダ", 2100, Class2Win, "3歳以上"), code: "DI2"},
+		{race: newClassifiedRace("中山", "ダ", 2400, Class1Win, "3歳"), code: "DL1"},
+		{race: newClassifiedRace("中山", "障", 4100, ClassOpen, "3歳以上"), code: "S"},
+		{race: newClassifiedRace("中山", "芝", 1600, "", ""), code: "TM1"},
+	}
+
+	for _, tt := range tests {
+		if code := DetermineClassificationCode(tt.race); code != tt.code {
+			t.Errorf("DetermineClassificationCode(%s %d %s %s) = %q, want %q", tt.race.Surface, tt.race.Distance, tt.race.Class.String, tt.race.AgeCondition.String, code, tt.code)
+		}
+	}
+}
+
+const testClassificationSchemes = `
+classification "venue" {
+  rule "jump" {
+    surfaces = ["障"]
+  }
+
+  dimension "venue" {
+    value "K" {
+      courses = ["東京", "中山"]
+    }
+    value "R" {}
+  }
+
+  dimension "distance" {
+    value "S" {
+      max_distance = 1400
+    }
+    value "M" {
+      min_distance = 1401
+      max_distance = 2000
+    }
+  }
+}
+
+classification "open" {
+  rule "G" {
+    classes = ["オープン"]
+  }
+}
+`
+
+func TestClassifyRace(t *testing.T) {
+	var config struct {
+		Classifications []*ClassificationScheme `hcl:"classification,block"`
+	}
+
+	if err := hclsimple.Decode("config.hcl", []byte(testClassificationSchemes), nil, &config); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CheckClassificationSchemes(config.Classifications); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		race  *Race
+		codes map[string]string
+	}{
+		{
+			race:  newClassifiedRace("東京", "芝", 1400, ClassOpen, "3歳以上"),
+			codes: map[string]string{DefaultClassificationScheme: "TM3", "venue": "KS", "open": "G"},
+		},
+		{
+			race:  newClassifiedRace("阪神", "ダ", 1800, Class1Win, "3歳"),
+			codes: map[string]string{DefaultClassificationScheme: "DM1", "venue": "RM"},
+		},
+		{
+			race:  newClassifiedRace("札幌", "芝", 2600, Class2Win, "3歳以上"),
+			codes: map[string]string{DefaultClassificationScheme: "TL2"},
+		},
+		{
+			race:  newClassifiedRace("中山", "障", 4100, ClassOpen, "3歳以上"),
+			codes: map[string]string{DefaultClassificationScheme: "S", "venue": "jump", "open": "G"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.race.ClassificationCode = DetermineClassificationCode(tt.race)
+
+		records := ClassifyRace(tt.race, config.Classifications)
+
+		codes := map[string]string{}
+		for i := 0; i < len(records); i++ {
+			codes[records[i].Scheme] = records[i].Code
+		}
+
+		if len(codes) != len(tt.codes) {
+			t.Errorf("ClassifyRace(%s %s %d) = %v, want %v", tt.race.Course, tt.race.Surface, tt.race.Distance, codes, tt.codes)
+			continue
+		}
+		for scheme, code := range tt.codes {
+			if codes[scheme] != code {
+				t.Errorf("ClassifyRace(%s %s %d) = %v, want %v", tt.race.Course, tt.race.Surface, tt.race.Distance, codes, tt.codes)
+				break
+			}
+		}
+	}
+}
+
+func TestCheckClassificationSchemes(t *testing.T) {
+	tests := []struct {
+		schemes []*ClassificationScheme
+		ok      bool
+	}{
+		{schemes: []*ClassificationScheme{{Name: "a", Rules: []*ClassificationRule{{Code: "A"}}}}, ok: true},
+		{schemes: []*ClassificationScheme{{Name: DefaultClassificationScheme, Rules: []*ClassificationRule{{Code: "A"}}}}},
+		{schemes: []*ClassificationScheme{{Name: "a", Rules: []*ClassificationRule{{Code: "A"}}}, {Name: "a", Rules: []*ClassificationRule{{Code: "B"}}}}},
+		{schemes: []*ClassificationScheme{{Name: "a"}}},
+	}
+
+	for i, tt := range tests {
+		if err := CheckClassificationSchemes(tt.schemes); (err == nil) != tt.ok {
+			t.Errorf("#%d: CheckClassificationSchemes() = %v, want ok %v", i, err, tt.ok)
+		}
+	}
+}
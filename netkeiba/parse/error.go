@@ -0,0 +1,61 @@
+package parse
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Error is an error, or a warning, found in a page. It tells where the
+// value came from as far as it is known.
+type Error struct {
+	File   string
+	RaceID RaceID
+	Table  string
+	Row    int
+	Field  string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	var s []string
+
+	if e.File != "" {
+		s = append(s, e.File)
+	}
+	if e.RaceID != 0 {
+		s = append(s, "race "+e.RaceID.String())
+	}
+	if e.Table != "" {
+		s = append(s, e.Table+" table")
+	}
+	if 0 < e.Row {
+		s = append(s, "row "+strconv.Itoa(e.Row))
+	}
+	if e.Field != "" {
+		s = append(s, "field "+e.Field)
+	}
+
+	return strings.Join(append(s, e.Err.Error()), ": ")
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithFile sets the file to the Error in the chain of err, or wraps err in an
+// Error if there is none.
+func WithFile(err error, file string) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if xerrors.As(err, &e) {
+		e.File = file
+		return err
+	}
+
+	return &Error{File: file, Err: err}
+}
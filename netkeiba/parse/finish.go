@@ -0,0 +1,124 @@
+package parse
+
+import (
+	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	FinishStatusFinished     = "finished"
+	FinishStatusPulledUp     = "pulled_up"
+	FinishStatusExcluded     = "excluded"
+	FinishStatusScratched    = "scratched"
+	FinishStatusDisqualified = "disqualified"
+)
+
+// finishStatuses maps the order of finish of the horses which have no
+// placing, abbreviated or not, to their status.
+var finishStatuses = map[string]string{
+	"中":  FinishStatusPulledUp,
+	"中止": FinishStatusPulledUp,
+	"除":  FinishStatusExcluded,
+	"除外": FinishStatusExcluded,
+	"取":  FinishStatusScratched,
+	"取消": FinishStatusScratched,
+	"失":  FinishStatusDisqualified,
+	"失格": FinishStatusDisqualified,
+}
+
+// finish is the order of finish decoded.
+type finish struct {
+	position sql.NullInt32
+	status   string
+	demoted  bool
+}
+
+// decodeOrderOfFinish decodes the order of finish, which looks like "1",
+// "3(降)" for the demoted horses, or "中" for the horses which did not
+// finish.
+func decodeOrderOfFinish(s string) (*finish, error) {
+	s = strings.TrimSpace(s)
+
+	if status, ok := finishStatuses[s]; ok {
+		return &finish{status: status}, nil
+	}
+
+	r := regexp.MustCompile(`^(\d+)\s*([(（]降[)）])?$`)
+
+	m := r.FindStringSubmatch(s)
+	if m == nil {
+		return nil, xerrors.Errorf("unexpected order of finish %q", s)
+	}
+
+	i, err := strconv.Atoi(m[1])
+	if err != nil {
+		return nil, err
+	}
+
+	f := &finish{status: FinishStatusFinished, demoted: m[2] != ""}
+	f.position.Scan(i)
+
+	return f, nil
+}
+
+// started reports whether the horse of the finish status started the race,
+// i.e. was neither scratched nor excluded.
+func started(status sql.NullString) bool {
+	return status.String != FinishStatusScratched && status.String != FinishStatusExcluded
+}
+
+// markDeadHeats flags the horses which finished in the same placing as
+// another.
+func markDeadHeats(records []*Result) {
+	count := make(map[int32]int)
+
+	for i := 0; i < len(records); i++ {
+		if records[i].FinishPosition.Valid {
+			count[records[i].FinishPosition.Int32]++
+		}
+	}
+
+	for i := 0; i < len(records); i++ {
+		if records[i].FinishPosition.Valid && 1 < count[records[i].FinishPosition.Int32] {
+			records[i].DeadHeat = true
+		}
+	}
+}
+
+// estimateOriginalPlacings estimates the placings the demoted horses finished
+// in, which the page does not show, by counting the horses with faster finish
+// times. The times are to a tenth of a second, so the placing is left NULL
+// when the time of the demoted horse is the same as another's, or when the
+// time of the demoted horse or of another horse which finished is missing.
+func estimateOriginalPlacings(records []*Result) {
+	for i := 0; i < len(records); i++ {
+		if !records[i].Demoted || !records[i].TimeSec.Valid {
+			continue
+		}
+
+		placing := 1
+		known := true
+		for j := 0; j < len(records); j++ {
+			if j == i || !records[j].FinishPosition.Valid {
+				continue
+			}
+
+			switch {
+			case !records[j].TimeSec.Valid:
+				known = false
+			case records[j].TimeSec.Float64 < records[i].TimeSec.Float64:
+				placing++
+			case records[j].TimeSec.Float64 == records[i].TimeSec.Float64:
+				known = false
+			}
+		}
+
+		if known {
+			records[i].OriginalPlacing.Scan(placing)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package parse
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestEstimateOriginalPlacings(t *testing.T) {
+	// result returns the result of a finished horse, with no time if time is
+	// 0.
+	result := func(position int, time float64, demoted bool) *Result {
+		r := &Result{Demoted: demoted}
+		r.FinishPosition.Scan(position)
+		if 0 < time {
+			r.TimeSec.Scan(time)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		records []*Result
+		want    sql.NullInt32
+	}{
+		{
+			name:    "faster than the horse placed before",
+			records: []*Result{result(1, 95.0, false), result(2, 95.3, false), result(3, 95.2, true)},
+			want:    sql.NullInt32{Int32: 2, Valid: true},
+		},
+		{
+			name:    "same time as another",
+			records: []*Result{result(1, 95.0, false), result(2, 95.2, false), result(3, 95.2, true)},
+		},
+		{
+			name:    "time missing",
+			records: []*Result{result(1, 95.0, false), result(2, 95.3, false), result(3, 0, true)},
+		},
+		{
+			name:    "time of another missing",
+			records: []*Result{result(1, 95.0, false), result(2, 0, false), result(3, 95.2, true)},
+		},
+		{
+			name: "another did not finish",
+			records: []*Result{
+				result(1, 95.0, false),
+				result(2, 95.3, false),
+				result(3, 95.2, true),
+				{FinishStatus: sql.NullString{String: FinishStatusPulledUp, Valid: true}},
+			},
+			want: sql.NullInt32{Int32: 2, Valid: true},
+		},
+	}
+
+	for _, tt := range tests {
+		estimateOriginalPlacings(tt.records)
+
+		if got := tt.records[2].OriginalPlacing; got != tt.want {
+			t.Errorf("%s: OriginalPlacing = %v, want %v", tt.name, got, tt.want)
+		}
+		if tt.records[0].OriginalPlacing.Valid || tt.records[1].OriginalPlacing.Valid {
+			t.Errorf("%s: OriginalPlacing of the horses not demoted", tt.name)
+		}
+	}
+}
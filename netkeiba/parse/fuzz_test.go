@@ -0,0 +1,146 @@
+package parse
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// addSeedPages adds the pages matched by pattern to the seed corpus, so that
+// the fuzzer mutates the markup of real pages.
+func addSeedPages(f *testing.F, pattern string) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for i := 0; i < len(files); i++ {
+		b, err := ioutil.ReadFile(files[i])
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+}
+
+func FuzzParseFinishTime(f *testing.F) {
+	for _, s := range []string{"1:23.4", "0:54.2", "3:45.7", "", ":", "1:", ":1", "1:2:3", "**"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		sec, err := util.parseFinishTime(s)
+		if err != nil && sec != 0 {
+			t.Errorf("parseFinishTime(%q) = %v with error %v", s, sec, err)
+		}
+	})
+}
+
+func FuzzUtil(f *testing.F) {
+	for _, s := range []string{
+		`<a href="/horse/2018105027/">サンプルホース</a><br />2018 鹿毛`,
+		`<td>[東] <a href="/trainer/01061/">調教師</a></td>`,
+		`<td>1,234.5</td>`,
+		`<a href="/">/</a>`,
+		`<a href="">`,
+		``,
+	} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		doc, err := Parse(bytes.NewReader([]byte(s)))
+		if err != nil {
+			return
+		}
+
+		util.htmlInnerText(doc)
+		util.htmlInnerTextAsInt(doc)
+		util.htmlInnerTextAsFloat(doc)
+		util.htmlAnchorHref(doc)
+		util.htmlSelectHrefLastSegment(doc)
+		util.htmlInnerTextAndSplit(doc, " ")
+		util.htmlInnerTextFirstLine(doc)
+		util.htmlInnerTextFirstRow(doc)
+		util.htmlSplitLineBreak(doc)
+		util.atoi(s)
+		util.parseFloat(s)
+	})
+}
+
+func FuzzBuildRacePage(f *testing.F) {
+	addSeedPages(f, "testdata/race/*.html")
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		doc, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			return
+		}
+
+		BuildRaceRecord(202105021211, doc)
+		BuildPayoutRecords(202105021211, doc)
+		BuildResultRecords(202105021211, doc)
+
+		page, err := BuildRacePage(202105021211, doc)
+		if err == nil && (page.Race == nil || len(page.Payouts) == 0 || len(page.Results) == 0) {
+			t.Errorf("incomplete page without error: %+v", page)
+		}
+	})
+}
+
+func FuzzBuildHorseRecords(f *testing.F) {
+	addSeedPages(f, "testdata/horse/*.html")
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		doc, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			return
+		}
+
+		records, err := BuildHorseRecords("2018105027", doc)
+		if err == nil && len(records) != 63 {
+			t.Errorf("%d records, expected 63", len(records))
+		}
+	})
+}
+
+func FuzzBuildWorkoutRecords(f *testing.F) {
+	f.Add([]byte(`<table class="OikiriTable"><tr class="HorseList">
+<td><a href="/horse/2018105027/">サンプルホース</a></td>
+<td>2021/05/26(水)</td><td>美Ｗ</td><td>良</td><td>助手</td>
+<td><ul><li>82.9(15.2)</li><li>67.7(14.0)</li><li>53.7(12.8)</li><li>40.9(12.6)</li><li>12.1(12.1)</li></ul></td>
+<td>馬なり</td>
+</tr></table>`))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		doc, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			return
+		}
+
+		BuildWorkoutRecords(202105021211, doc)
+	})
+}
+
+func FuzzBuildProfileRecords(f *testing.F) {
+	f.Add([]byte(`<div class="db_head_name"><h1>騎手イチ (キシュイチ)</h1><p class="txt_01">1969/03/15<br />栗東(フリー)</p></div>
+<table><tr><th>免許取得年</th><td>1987年</td></tr></table>
+<table class="race_table_01">
+<tr><td>2021</td><td>3</td><td>80</td><td>70</td><td>60</td><td>400</td><td></td><td></td><td></td><td></td><td></td><td></td><td></td><td></td><td></td><td></td><td>0.131</td><td></td><td></td><td>1,234,567.0</td></tr>
+</table>
+<div class="horse_title"><h1>サンプルホース</h1></div>
+<table class="db_prof_table"><tr><th>生産者</th><td><a href="/breeder/373126/">生産者イチ</a></td></tr><tr><th>産地</th><td>新ひだか町</td></tr></table>`))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		doc, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			return
+		}
+
+		BuildProfileRecord("01126", doc)
+		BuildProfileStatsRecords("01126", doc)
+		BuildBreederRecord("373126", doc)
+		BuildHorseProfileRecord("2018105027", doc)
+	})
+}
@@ -0,0 +1,117 @@
+package parse
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestRacePage builds the race result pages in testdata/race, which are
+// named after the race ID and the case they cover.
+func TestRacePage(t *testing.T) {
+	testGolden(t, "testdata/race/*.html", func(name string, r io.Reader) (interface{}, error) {
+		id, err := ParseRaceID(strings.SplitN(name, "_", 2)[0])
+		if err != nil {
+			return nil, err
+		}
+
+		page, err := ReadRacePage(id, r)
+		if err != nil {
+			return nil, err
+		}
+
+		warnings := make([]string, len(page.Warnings))
+		for i := 0; i < len(page.Warnings); i++ {
+			warnings[i] = page.Warnings[i].Error()
+		}
+
+		return struct {
+			*RacePage
+			Warnings []string `json:"warnings"`
+		}{page, warnings}, nil
+	})
+}
+
+// TestHorseRecords builds the pedigree pages in testdata/horse, which are
+// named after the horse ID.
+func TestHorseRecords(t *testing.T) {
+	testGolden(t, "testdata/horse/*.html", func(name string, r io.Reader) (interface{}, error) {
+		doc, err := Parse(r)
+		if err != nil {
+			return nil, err
+		}
+
+		records, err := BuildHorseRecords(name, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{
+			"horses":            records,
+			"unknown_ancestors": UnknownAncestors(records),
+		}, nil
+	})
+}
+
+// testGolden compares the JSON of the records built from each page matched
+// by pattern with the golden file next to it, or rewrites the golden files
+// when the -update flag is given.
+func testGolden(t *testing.T, pattern string, build func(name string, r io.Reader) (interface{}, error)) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no pages match %s", pattern)
+	}
+
+	for i := 0; i < len(files); i++ {
+		file := files[i]
+		name := strings.TrimSuffix(filepath.Base(file), ".html")
+
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			v, err := build(name, f)
+			if err != nil {
+				v = map[string]string{"error": err.Error()}
+			}
+
+			got, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := strings.TrimSuffix(file, ".html") + ".golden.json"
+
+			if *update {
+				if err := ioutil.WriteFile(golden, got, 0666); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%s, run go test with -update to create it", err)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s differs from %s, run go test with -update if the change is intended\ngot:\n%s", file, golden, got)
+			}
+		})
+	}
+}
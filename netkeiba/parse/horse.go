@@ -0,0 +1,342 @@
+package parse
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+	"golang.org/x/xerrors"
+)
+
+// Horse is a row of the horse table.
+type Horse struct {
+	ID     HorseID
+	Name   string
+	SireID sql.NullString
+	DamID  sql.NullString
+}
+
+func (h *Horse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"id":      h.ID,
+		"name":    h.Name,
+		"sire_id": util.nullable(h.SireID),
+		"dam_id":  util.nullable(h.DamID),
+	})
+}
+
+// BuildHorseRecords builds the records of the horse and its 62 ancestors from
+// the 5-generation pedigree page. The records are named after their place in
+// the pedigree, e.g.
+//
+//	(a) Deep Impact
+//	|– (b1) Sunday Silence
+//	|   |– (c1) Halo
+//	|   | |- (d1) Hail to Reason
+//	|   | | |- (e1) Turn-to
+//	|   | | | |- (f1) Royal Charger
+//	|   | | | `– (f2) Source Sucree
+//	|   | | `– (e2) Nothirdchance
+//	|   | |   |- (f3) Blue Swords
+//	|   | |   `– (f4) Galla Colors
+//	|   | `– (d2) Cosmah
+//	|   |   |- (e3) Cosmic Bomb
+//	|   |   | |- (f5) Pharamond
+//	|   |   | `– (f6) Banish Fear
+//	|   |   `– (e4) Almahmoud
+//	|   |     |- (f7) Mahmoud
+//	|   |     `– (f8) Arbitrator
+//	|   `– (c2) Wishing Well
+//	|     |- (d3) Understanding
+//	|     | |– (e5) Promised Land
+//	|     | | |- (f9) Palestinian
+//	|     | | `– (f10) Mahmoudess
+//	|     | `– (e6) Pretty Ways
+//	|     |   |- (f11) Stymie
+//	|     |   `– (f12) Pretty Jo
+//	|     `– (d4) Mountain Flower
+//	|       |- (e7) Montparnasse
+//	|       | |- (f13) Gulf Stream
+//	|       | `– (f14) Mignon
+//	|       `– (e8) Edelweiss
+//	|         |- (f15) Hillary
+//	|         `– (f16) Dowager
+//	|
+//	`– (b2) Wind in Her Hair
+//	    |– (c3) Alzao
+//	    | |- (d5) Lyphard
+//	    | | |- (e9) Northern Dancer
+//	    | | | |- (f17) Nearctic
+//	    | | | `– (f18) Natalma
+//	    | | `– (e10) Goofed
+//	    | |   |- (f19) Court Martial
+//	    | |   `– (f20) Barra
+//	    | `– (d6)Lady Rebecca
+//	    |   |- (e11) Sir Ivor
+//	    |   | |- (f21) Sir Gaylord
+//	    |   | `– (f22) Attica
+//	    |   `– (e12) Pocahontas
+//	    |     |- (f23) Roman
+//	    |     `– (f24) Arbitrator
+//	    `– (c4) Burghclere
+//	      |- (d7) Busted
+//	      | |– (e13) Crepello
+//	      | | |- (f25) Donatello
+//	      | | `– (f26) Crepuscule
+//	      | `– (e14) Sans le Sou
+//	      |   |- (f27) ヴィミー
+//	      |   `– (f28) Martial Loan
+//	      `– (d8) Highclere
+//	        |- (e15) Queen's Hussar
+//	        | |- (f29) March Past
+//	        | `– (f30) Jojo
+//	        `– (e16) Highlight
+//	          |- (f31) Borealis
+//	          `– (f32) Hypericum
+func BuildHorseRecords(id string, doc *html.Node) ([]*Horse, error) {
+	tr := htmlquery.QuerySelectorAll(doc, xpath.MustCompile(`//table[`+util.xpathContains("@class", "blood_table")+`]/tbody/tr`))
+	if len(tr) != 32 {
+		return nil, xerrors.Errorf(`<tr> 要素の数が 32 ではない（horse_id: %s）: %d`, id, len(tr))
+	}
+
+	f1 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[0], xpath.MustCompile(`/td[5]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[0], xpath.MustCompile(`/td[5]/a[1]/span|/td[5]/a[1]`)))}
+	f2 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[1], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[1], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f3 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[2], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[2], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	f4 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[3], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[3], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f5 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[4], xpath.MustCompile(`/td[3]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[4], xpath.MustCompile(`/td[3]/a[1]/span|/td[3]/a[1]`)))}
+	f6 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[5], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[5], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f7 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[6], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[6], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	f8 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[7], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[7], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f9 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[8], xpath.MustCompile(`/td[4]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[8], xpath.MustCompile(`/td[4]/a[1]/span|/td[4]/a[1]`)))}
+	f10 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[9], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[9], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f11 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[10], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[10], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	f12 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[11], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[11], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f13 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[12], xpath.MustCompile(`/td[3]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[12], xpath.MustCompile(`/td[3]/a[1]/span|/td[3]/a[1]`)))}
+	f14 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[13], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[13], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f15 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[14], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[14], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	f16 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[15], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[15], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+
+	e1 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[0], xpath.MustCompile(`/td[4]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[0], xpath.MustCompile(`/td[4]/a[1]/span|/td[4]/a[1]`)))}
+	e1.SireID.Scan(f1.ID)
+	e1.DamID.Scan(f2.ID)
+
+	e2 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[2], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[2], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	e2.SireID.Scan(f3.ID)
+	e2.DamID.Scan(f4.ID)
+
+	e3 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[4], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[4], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	e3.SireID.Scan(f5.ID)
+	e3.DamID.Scan(f6.ID)
+
+	e4 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[6], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[6], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	e4.SireID.Scan(f7.ID)
+	e4.DamID.Scan(f8.ID)
+
+	e5 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[8], xpath.MustCompile(`/td[3]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[8], xpath.MustCompile(`/td[3]/a[1]/span|/td[3]/a[1]`)))}
+	e5.SireID.Scan(f9.ID)
+	e5.DamID.Scan(f10.ID)
+
+	e6 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[10], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[10], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	e6.SireID.Scan(f11.ID)
+	e6.DamID.Scan(f12.ID)
+
+	e7 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[12], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[12], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	e7.SireID.Scan(f13.ID)
+	e7.DamID.Scan(f14.ID)
+
+	e8 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[14], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[14], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	e8.SireID.Scan(f15.ID)
+	e8.DamID.Scan(f16.ID)
+
+	d1 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[0], xpath.MustCompile(`/td[3]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[0], xpath.MustCompile(`/td[3]/a[1]/span|/td[3]/a[1]`)))}
+	d1.SireID.Scan(e1.ID)
+	d1.DamID.Scan(e2.ID)
+
+	d2 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[4], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[4], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	d2.SireID.Scan(e3.ID)
+	d2.DamID.Scan(e4.ID)
+
+	d3 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[8], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[8], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	d3.SireID.Scan(e5.ID)
+	d3.DamID.Scan(e6.ID)
+
+	d4 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[12], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[12], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	d4.SireID.Scan(e7.ID)
+	d4.DamID.Scan(e8.ID)
+
+	c1 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[0], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[0], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	c1.SireID.Scan(d1.ID)
+	c1.DamID.Scan(d2.ID)
+
+	c2 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[8], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[8], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	c2.SireID.Scan(d3.ID)
+	c2.DamID.Scan(d4.ID)
+
+	b1 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(tr[0])), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[0], xpath.MustCompile(`/td[1]/a[1]`)))}
+	b1.SireID.Scan(c1.ID)
+	b1.DamID.Scan(c2.ID)
+
+	f17 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[16], xpath.MustCompile(`/td[5]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[16], xpath.MustCompile(`/td[5]/a[1]/span|/td[5]/a[1]`)))}
+	f18 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[17], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[17], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f19 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[18], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[18], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	f20 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[19], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[19], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f21 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[20], xpath.MustCompile(`/td[3]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[20], xpath.MustCompile(`/td[3]/a[1]/span|/td[3]/a[1]`)))}
+	f22 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[21], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[21], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f23 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[22], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[22], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	f24 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[23], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[23], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f25 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[24], xpath.MustCompile(`/td[4]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[24], xpath.MustCompile(`/td[4]/a[1]/span|/td[4]/a[1]`)))}
+	f26 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[25], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[25], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f27 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[26], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[26], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	f28 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[27], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[27], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f29 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[28], xpath.MustCompile(`/td[3]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[28], xpath.MustCompile(`/td[3]/a[1]/span|/td[3]/a[1]`)))}
+	f30 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[29], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[29], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	f31 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[30], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[30], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	f32 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[31], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[31], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+
+	e9 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[16], xpath.MustCompile(`/td[4]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[16], xpath.MustCompile(`/td[4]/a[1]/span|/td[4]/a[1]`)))}
+	e9.SireID.Scan(f17.ID)
+	e9.DamID.Scan(f18.ID)
+
+	e10 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[18], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[18], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	e10.SireID.Scan(f19.ID)
+	e10.DamID.Scan(f20.ID)
+
+	e11 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[20], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[20], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	e11.SireID.Scan(f21.ID)
+	e11.DamID.Scan(f22.ID)
+
+	e12 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[22], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[22], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	e12.SireID.Scan(f23.ID)
+	e12.DamID.Scan(f24.ID)
+
+	e13 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[24], xpath.MustCompile(`/td[3]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[24], xpath.MustCompile(`/td[3]/a[1]/span|/td[3]/a[1]`)))}
+	e13.SireID.Scan(f25.ID)
+	e13.DamID.Scan(f26.ID)
+
+	e14 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[26], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[26], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	e14.SireID.Scan(f27.ID)
+	e14.DamID.Scan(f28.ID)
+
+	e15 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[28], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[28], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	e15.SireID.Scan(f29.ID)
+	e15.DamID.Scan(f30.ID)
+
+	e16 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[30], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[30], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	e16.SireID.Scan(f31.ID)
+	e16.DamID.Scan(f32.ID)
+
+	d5 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[16], xpath.MustCompile(`/td[3]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[16], xpath.MustCompile(`/td[3]/a[1]/span|/td[3]/a[1]`)))}
+	d5.SireID.Scan(e9.ID)
+	d5.DamID.Scan(e10.ID)
+
+	d6 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[20], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[20], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	d6.SireID.Scan(e11.ID)
+	d6.DamID.Scan(e12.ID)
+
+	d7 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[24], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[24], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	d7.SireID.Scan(e13.ID)
+	d7.DamID.Scan(e14.ID)
+
+	d8 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[28], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[28], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	d8.SireID.Scan(e15.ID)
+	d8.DamID.Scan(e16.ID)
+
+	c3 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[16], xpath.MustCompile(`/td[2]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[16], xpath.MustCompile(`/td[2]/a[1]/span|/td[2]/a[1]`)))}
+	c3.SireID.Scan(d5.ID)
+	c3.DamID.Scan(d6.ID)
+
+	c4 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(htmlquery.QuerySelector(tr[24], xpath.MustCompile(`/td[1]/a[1]`)))), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[24], xpath.MustCompile(`/td[1]/a[1]/span|/td[1]/a[1]`)))}
+	c4.SireID.Scan(d7.ID)
+	c4.DamID.Scan(d8.ID)
+
+	b2 := &Horse{ID: HorseID(util.htmlSelectHrefLastSegment(tr[16])), Name: util.htmlInnerTextFirstLine(htmlquery.QuerySelector(tr[16], xpath.MustCompile(`/td[1]/a[1]`)))}
+	b2.SireID.Scan(c3.ID)
+	b2.DamID.Scan(c4.ID)
+	name := util.htmlInnerText(htmlquery.QuerySelector(doc, xpath.MustCompile(`//div[`+util.xpathContains("@class", "horse_title")+`]/h1`)))
+
+	a := &Horse{ID: HorseID(id), Name: name}
+	a.SireID.Scan(b1.ID)
+	a.DamID.Scan(b2.ID)
+
+	return []*Horse{
+		a,
+		b1,
+		b2,
+		c1,
+		c2,
+		c3,
+		c4,
+		d1,
+		d2,
+		d3,
+		d4,
+		d5,
+		d6,
+		d7,
+		d8,
+		e1,
+		e2,
+		e3,
+		e4,
+		e5,
+		e6,
+		e7,
+		e8,
+		e9,
+		e10,
+		e11,
+		e12,
+		e13,
+		e14,
+		e15,
+		e16,
+		f1,
+		f2,
+		f3,
+		f4,
+		f5,
+		f6,
+		f7,
+		f8,
+		f9,
+		f10,
+		f11,
+		f12,
+		f13,
+		f14,
+		f15,
+		f16,
+		f17,
+		f18,
+		f19,
+		f20,
+		f21,
+		f22,
+		f23,
+		f24,
+		f25,
+		f26,
+		f27,
+		f28,
+		f29,
+		f30,
+		f31,
+		f32,
+	}, nil
+}
+
+// UnknownAncestors returns the ancestors in the pedigree records whose
+// parents are not on the page.
+func UnknownAncestors(records []*Horse) []string {
+	var ancestors []string
+
+	for i := 0; i < len(records); i++ {
+		if records[i].ID != "" && !records[i].SireID.Valid && !records[i].DamID.Valid {
+			ancestors = append(ancestors, string(records[i].ID))
+		}
+	}
+
+	return ancestors
+}
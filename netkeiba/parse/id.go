@@ -0,0 +1,206 @@
+package parse
+
+import (
+	"regexp"
+	"strconv"
+
+	"golang.org/x/xerrors"
+)
+
+// RaceID is the ID of a race, e.g. 202105021211 for the 11th race of the 12th
+// day of the 2nd meeting at Tokyo in 2021.
+type RaceID int
+
+// HorseID is the ID of a horse, e.g. "2018105027", or "000a00fe05" for the
+// horses bred abroad and the old horses.
+type HorseID string
+
+// JockeyID is the ID of a jockey, e.g. "01126".
+type JockeyID string
+
+// TrainerID is the ID of a trainer, e.g. "01061".
+type TrainerID string
+
+// OwnerID is the ID of an owner, e.g. "226800" or "x00aa4".
+type OwnerID string
+
+// BreederID is the ID of a breeder, e.g. "373126".
+type BreederID string
+
+var (
+	raceIDPattern    = regexp.MustCompile(`^\d{12}$`)
+	horseIDPattern   = regexp.MustCompile(`^[0-9a-z]{10}$`)
+	jockeyIDPattern  = regexp.MustCompile(`^[0-9a-z]{5}$`)
+	trainerIDPattern = regexp.MustCompile(`^[0-9a-z]{5}$`)
+	ownerIDPattern   = regexp.MustCompile(`^[0-9a-z]{6}$`)
+	breederIDPattern = regexp.MustCompile(`^[0-9a-z]{6}$`)
+)
+
+// parseID validates the ID of the kind, which is empty when the link is
+// missing from the page.
+func parseID(kind string, pattern *regexp.Regexp, s string) error {
+	if s == "" {
+		return xerrors.Errorf("missing %s ID", kind)
+	}
+	if !pattern.MatchString(s) {
+		return xerrors.Errorf("invalid %s ID %q", kind, s)
+	}
+	return nil
+}
+
+// ParseRaceID parses the race ID.
+func ParseRaceID(s string) (RaceID, error) {
+	if err := parseID("race", raceIDPattern, s); err != nil {
+		return 0, err
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+
+	return RaceID(i), nil
+}
+
+func (id RaceID) String() string {
+	return strconv.Itoa(int(id))
+}
+
+// Path returns the path of the race result page on the database site.
+func (id RaceID) Path() string {
+	return "/race/" + id.String() + "/"
+}
+
+// WorkoutPath returns the path of the workout page of the race on the race
+// site.
+func (id RaceID) WorkoutPath() string {
+	return "/race/oikiri.html?race_id=" + id.String()
+}
+
+// ParseHorseID parses the horse ID.
+func ParseHorseID(s string) (HorseID, error) {
+	if err := parseID("horse", horseIDPattern, s); err != nil {
+		return "", err
+	}
+	return HorseID(s), nil
+}
+
+func (id HorseID) String() string {
+	return string(id)
+}
+
+// Path returns the path of the horse profile page on the database site.
+func (id HorseID) Path() string {
+	return "/horse/" + id.String() + "/"
+}
+
+// PedigreePath returns the path of the 5-generation pedigree page on the
+// database site.
+func (id HorseID) PedigreePath() string {
+	return "/horse/ped/" + id.String()
+}
+
+// ParseJockeyID parses the jockey ID.
+func ParseJockeyID(s string) (JockeyID, error) {
+	if err := parseID("jockey", jockeyIDPattern, s); err != nil {
+		return "", err
+	}
+	return JockeyID(s), nil
+}
+
+func (id JockeyID) String() string {
+	return string(id)
+}
+
+// Path returns the path of the jockey profile page on the database site.
+func (id JockeyID) Path() string {
+	return "/jockey/" + id.String() + "/"
+}
+
+// ParseTrainerID parses the trainer ID.
+func ParseTrainerID(s string) (TrainerID, error) {
+	if err := parseID("trainer", trainerIDPattern, s); err != nil {
+		return "", err
+	}
+	return TrainerID(s), nil
+}
+
+func (id TrainerID) String() string {
+	return string(id)
+}
+
+// Path returns the path of the trainer profile page on the database site.
+func (id TrainerID) Path() string {
+	return "/trainer/" + id.String() + "/"
+}
+
+// ParseOwnerID parses the owner ID.
+func ParseOwnerID(s string) (OwnerID, error) {
+	if err := parseID("owner", ownerIDPattern, s); err != nil {
+		return "", err
+	}
+	return OwnerID(s), nil
+}
+
+func (id OwnerID) String() string {
+	return string(id)
+}
+
+// Path returns the path of the owner profile page on the database site.
+func (id OwnerID) Path() string {
+	return "/owner/" + id.String() + "/"
+}
+
+// ParseBreederID parses the breeder ID.
+func ParseBreederID(s string) (BreederID, error) {
+	if err := parseID("breeder", breederIDPattern, s); err != nil {
+		return "", err
+	}
+	return BreederID(s), nil
+}
+
+func (id BreederID) String() string {
+	return string(id)
+}
+
+// Path returns the path of the breeder profile page on the database site.
+func (id BreederID) Path() string {
+	return "/breeder/" + id.String() + "/"
+}
+
+// ProfilePath returns the path of the profile page of the kind on the
+// database site, or an empty string if the ID is invalid.
+func ProfilePath(kind string, s string) (string, error) {
+	var path string
+
+	switch kind {
+	case ProfileKindJockey:
+		id, err := ParseJockeyID(s)
+		if err != nil {
+			return "", err
+		}
+		path = id.Path()
+	case ProfileKindTrainer:
+		id, err := ParseTrainerID(s)
+		if err != nil {
+			return "", err
+		}
+		path = id.Path()
+	case ProfileKindOwner:
+		id, err := ParseOwnerID(s)
+		if err != nil {
+			return "", err
+		}
+		path = id.Path()
+	case ProfileKindBreeder:
+		id, err := ParseBreederID(s)
+		if err != nil {
+			return "", err
+		}
+		path = id.Path()
+	default:
+		return "", xerrors.Errorf("unknown profile kind %q", kind)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,102 @@
+package parse
+
+import "testing"
+
+func TestParseID(t *testing.T) {
+	tests := []struct {
+		parse func(s string) (string, error)
+		s     string
+		path  string
+		err   bool
+	}{
+		{parse: parseRacePath, s: "202105021211", path: "/race/202105021211/"},
+		{parse: parseRacePath, s: "", err: true},
+		{parse: parseRacePath, s: "2021050212", err: true},
+		{parse: parseHorsePath, s: "2018105027", path: "/horse/2018105027/"},
+		{parse: parseHorsePath, s: "000a00fe05", path: "/horse/000a00fe05/"},
+		{parse: parseHorsePath, s: "", err: true},
+		{parse: parseHorsePath, s: "0", err: true},
+		{parse: parseJockeyPath, s: "01126", path: "/jockey/01126/"},
+		{parse: parseJockeyPath, s: "ped", err: true},
+		{parse: parseTrainerPath, s: "01061", path: "/trainer/01061/"},
+		{parse: parseTrainerPath, s: "", err: true},
+		{parse: parseOwnerPath, s: "x00aa4", path: "/owner/x00aa4/"},
+		{parse: parseOwnerPath, s: "X00AA4", err: true},
+	}
+
+	for _, tt := range tests {
+		path, err := tt.parse(tt.s)
+		if (err != nil) != tt.err {
+			t.Errorf("parse(%q) error = %v, want error %v", tt.s, err, tt.err)
+			continue
+		}
+		if path != tt.path {
+			t.Errorf("parse(%q) path = %q, want %q", tt.s, path, tt.path)
+		}
+	}
+}
+
+func parseRacePath(s string) (string, error) {
+	id, err := ParseRaceID(s)
+	if err != nil {
+		return "", err
+	}
+	return id.Path(), nil
+}
+
+func parseHorsePath(s string) (string, error) {
+	id, err := ParseHorseID(s)
+	if err != nil {
+		return "", err
+	}
+	return id.Path(), nil
+}
+
+func parseJockeyPath(s string) (string, error) {
+	id, err := ParseJockeyID(s)
+	if err != nil {
+		return "", err
+	}
+	return id.Path(), nil
+}
+
+func parseTrainerPath(s string) (string, error) {
+	id, err := ParseTrainerID(s)
+	if err != nil {
+		return "", err
+	}
+	return id.Path(), nil
+}
+
+func parseOwnerPath(s string) (string, error) {
+	id, err := ParseOwnerID(s)
+	if err != nil {
+		return "", err
+	}
+	return id.Path(), nil
+}
+
+func TestProfilePath(t *testing.T) {
+	tests := []struct {
+		kind string
+		s    string
+		path string
+		err  bool
+	}{
+		{kind: ProfileKindJockey, s: "01126", path: "/jockey/01126/"},
+		{kind: ProfileKindBreeder, s: "373126", path: "/breeder/373126/"},
+		{kind: ProfileKindTrainer, s: "", err: true},
+		{kind: ProfileKindOwner, s: "X00AA4", err: true},
+		{kind: "horse", s: "2018105027", err: true},
+	}
+
+	for _, tt := range tests {
+		path, err := ProfilePath(tt.kind, tt.s)
+		if (err != nil) != tt.err {
+			t.Errorf("ProfilePath(%q, %q) error = %v, want error %v", tt.kind, tt.s, err, tt.err)
+		}
+		if path != tt.path {
+			t.Errorf("ProfilePath(%q, %q) = %q, want %q", tt.kind, tt.s, path, tt.path)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package parse
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// marginLengths are the lengths of the margins shorter than half a length,
+// and of 大差, which is more than 10 lengths.
+var marginLengths = map[string]float64{
+	"同着":  0,
+	"ハナ":  0.05,
+	"アタマ": 0.1,
+	"クビ":  0.25,
+	"大":   10,
+	"大差":  10,
+}
+
+// ParseWinningMargin converts the margin to the horse ahead into lengths. The
+// margin looks like "ハナ", "1/2", "3", "1.1/4" for 1 and a quarter lengths,
+// or "大" for 大差.
+func ParseWinningMargin(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+
+	if l, ok := marginLengths[s]; ok {
+		return l, nil
+	}
+
+	r := regexp.MustCompile(`^(\d+)$|^(?:(\d+)\.)?(\d+)/(\d+)$`)
+
+	m := r.FindStringSubmatch(s)
+	if m == nil {
+		return 0, xerrors.Errorf("unexpected winning margin %q", s)
+	}
+
+	if m[1] != "" {
+		return strconv.ParseFloat(m[1], 64)
+	}
+
+	n, _ := strconv.Atoi(m[3])
+	d, _ := strconv.Atoi(m[4])
+	if d == 0 || d <= n {
+		return 0, xerrors.Errorf("unexpected winning margin %q", s)
+	}
+
+	l := float64(n) / float64(d)
+
+	if m[2] != "" {
+		i, _ := strconv.Atoi(m[2])
+		l += float64(i)
+	}
+
+	return l, nil
+}
+
+// countLengthsBehind sets the margins in lengths of the horses which
+// finished, and the lengths behind the winner summed up from them. The
+// lengths behind are left NULL after a margin which is not understood.
+func countLengthsBehind(records []*Result, warn func(i int, err error)) {
+	var behind float64
+	valid := true
+	first := true
+
+	for i := 0; i < len(records); i++ {
+		if !records[i].FinishPosition.Valid {
+			continue
+		}
+
+		if first {
+			first = false
+			records[i].MarginLengths.Scan(0.0)
+			records[i].LengthsBehind.Scan(0.0)
+			continue
+		}
+
+		l, err := ParseWinningMargin(records[i].WinningMargin)
+		if err != nil {
+			warn(i, err)
+			valid = false
+			continue
+		}
+
+		records[i].MarginLengths.Scan(l)
+
+		if valid {
+			behind += l
+			records[i].LengthsBehind.Scan(behind)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package parse
+
+import "testing"
+
+func TestParseWinningMargin(t *testing.T) {
+	tests := []struct {
+		margin  string
+		lengths float64
+		err     bool
+	}{
+		{margin: "同着", lengths: 0},
+		{margin: "ハナ", lengths: 0.05},
+		{margin: "アタマ", lengths: 0.1},
+		{margin: "クビ", lengths: 0.25},
+		{margin: "1/2", lengths: 0.5},
+		{margin: "3/4", lengths: 0.75},
+		{margin: "1", lengths: 1},
+		{margin: "1.1/4", lengths: 1.25},
+		{margin: "1.1/2", lengths: 1.5},
+		{margin: "1.3/4", lengths: 1.75},
+		{margin: "2.1/2", lengths: 2.5},
+		{margin: "10", lengths: 10},
+		{margin: "大", lengths: 10},
+		{margin: "大差", lengths: 10},
+		{margin: " クビ ", lengths: 0.25},
+		{margin: "", err: true},
+		{margin: "**", err: true},
+		{margin: "1/0", err: true},
+		{margin: "4/4", err: true},
+		{margin: "1.", err: true},
+		{margin: "1.1", err: true},
+	}
+
+	for _, tt := range tests {
+		lengths, err := ParseWinningMargin(tt.margin)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseWinningMargin(%q) error = %v, want error %v", tt.margin, err, tt.err)
+			continue
+		}
+		if lengths != tt.lengths {
+			t.Errorf("ParseWinningMargin(%q) = %v, want %v", tt.margin, lengths, tt.lengths)
+		}
+	}
+}
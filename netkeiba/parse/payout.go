@@ -0,0 +1,61 @@
+package parse
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	BetTypeWin             = "WIN"
+	BetTypePlace           = "PLACE"
+	BetTypeBracketQuinella = "BRACKET_QUINELLA"
+	BetTypeQuinella        = "QUINELLA"
+	BetTypeWide            = "WIDE"
+	BetTypeExacta          = "EXACTA"
+	BetTypeTrio            = "TRIO"
+	BetTypeTrifecta        = "TRIFECTA"
+)
+
+// betTypes maps the ticket types on the payout table to the bet types.
+var betTypes = map[string]string{
+	"単勝":  BetTypeWin,
+	"複勝":  BetTypePlace,
+	"枠連":  BetTypeBracketQuinella,
+	"馬連":  BetTypeQuinella,
+	"ワイド": BetTypeWide,
+	"馬単":  BetTypeExacta,
+	"三連複": BetTypeTrio,
+	"三連単": BetTypeTrifecta,
+}
+
+// isOrdered reports whether the order of the numbers of the bet type is
+// significant.
+func isOrdered(betType string) bool {
+	return betType == BetTypeExacta || betType == BetTypeTrifecta
+}
+
+// parseDraw parses the numbers of the draw, which looks like "3",
+// "3 - 11 - 14" or "3 → 11 → 14" for the ordered bet types. The numbers of
+// BRACKET_QUINELLA are bracket numbers, and the others horse numbers.
+func parseDraw(s string) ([]int, error) {
+	ss := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '-' || r == '→' || r == ' ' || r == ' '
+	})
+	if len(ss) == 0 {
+		return nil, xerrors.Errorf("unexpected draw %q", s)
+	}
+
+	numbers := make([]int, len(ss))
+
+	for i := 0; i < len(ss); i++ {
+		n, err := strconv.Atoi(ss[i])
+		if err != nil {
+			return nil, xerrors.Errorf("unexpected draw %q", s)
+		}
+		numbers[i] = n
+	}
+
+	return numbers, nil
+}
@@ -0,0 +1,103 @@
+package parse
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDraw(t *testing.T) {
+	tests := []struct {
+		draw    string
+		numbers []int
+		err     bool
+	}{
+		{draw: "3", numbers: []int{3}},
+		{draw: "3 - 11", numbers: []int{3, 11}},
+		{draw: "3 - 11 - 14", numbers: []int{3, 11, 14}},
+		{draw: "3 → 11 → 14", numbers: []int{3, 11, 14}},
+		{draw: "", err: true},
+		{draw: "3 - ?", err: true},
+	}
+
+	for _, tt := range tests {
+		numbers, err := parseDraw(tt.draw)
+		if (err != nil) != tt.err {
+			t.Errorf("parseDraw(%q) error = %v, want error %v", tt.draw, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(numbers, tt.numbers) {
+			t.Errorf("parseDraw(%q) = %v, want %v", tt.draw, numbers, tt.numbers)
+		}
+	}
+}
+
+// TestBuildPayoutRecordsSplit tests the draw whose dividend is paid twice.
+func TestBuildPayoutRecordsSplit(t *testing.T) {
+	doc, err := Parse(strings.NewReader(`<table class="pay_table_01"><tr>
+<th class="waku">枠連</th>
+<td>2 - 5<br />2 - 5</td>
+<td class="txt_r">260<br />1,340</td>
+<td class="txt_r">1<br />6</td>
+</tr></table>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payouts, err := BuildPayoutRecords(202101010411, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(payouts) != 2 {
+		t.Fatalf("%d payouts, want 2", len(payouts))
+	}
+	for i, amount := range []float64{260, 1340} {
+		if payouts[i].Split != i+1 || payouts[i].Amount != amount {
+			t.Errorf("payout %d is split %d of %v, want split %d of %v", i, payouts[i].Split, payouts[i].Amount, i+1, amount)
+		}
+	}
+}
+
+// TestRealDeadHeatPayouts checks that the pages of real dead heats have the
+// 単勝 or 複勝 payouts split between the horses in the dead heat. The page is saved from db.netkeiba.com as
+// described in testdata/README.md.
+func TestRealDeadHeatPayouts(t *testing.T) {
+	files, err := filepath.Glob("testdata/race/*_dead_heat_real.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Skip("no page of a real dead heat in testdata/race yet")
+	}
+
+	for i := 0; i < len(files); i++ {
+		id, err := ParseRaceID(strings.SplitN(filepath.Base(files[i]), "_", 2)[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := os.Open(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		page, err := ReadRacePage(id, f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %s", files[i], err)
+		}
+
+		counts := map[string]int{}
+		for j := 0; j < len(page.Payouts); j++ {
+			counts[page.Payouts[j].TicketType]++
+		}
+
+		// a dead heat pays a dividend per horse in it
+		if counts["単勝"] < 2 && counts["複勝"] < 4 {
+			t.Errorf("%s has %d 単勝 and %d 複勝 payouts, want the split rows of the dead heat", files[i], counts["単勝"], counts["複勝"])
+		}
+	}
+}
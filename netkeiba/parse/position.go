@@ -0,0 +1,79 @@
+package parse
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	RunningStyleFrontRunner = "逃げ"
+	RunningStyleStalker     = "先行"
+	RunningStyleCloser      = "差し"
+	RunningStyleDeepCloser  = "追込"
+)
+
+// parsePositions parses the positions at the corners, which look like
+// "3-3-2-1". The number of the corners depends on the course, and the
+// positions after a horse was pulled up are empty, e.g. "4-4-".
+func parsePositions(s string) ([]int, error) {
+	var positions []int
+
+	if s = strings.TrimSpace(s); s == "" {
+		return positions, nil
+	}
+
+	ss := strings.Split(s, "-")
+
+	for i := 0; i < len(ss); i++ {
+		if ss[i] == "" && i == len(ss)-1 {
+			break
+		}
+
+		p, err := strconv.Atoi(ss[i])
+		if err != nil || p < 1 {
+			return nil, xerrors.Errorf("unexpected positions %q", s)
+		}
+
+		positions = append(positions, p)
+	}
+
+	return positions, nil
+}
+
+// determineRunningStyle labels the running style by the position at the
+// first corner in the field: the leader is 逃げ, the first third of the
+// field 先行, the second third 差し and the rest 追込. The thirds are rounded
+// up, so that the second of a small field is 先行.
+func determineRunningStyle(first int, fieldSize int) sql.NullString {
+	var style sql.NullString
+
+	switch {
+	case first < 1 || fieldSize < 1:
+	case first == 1:
+		style.Scan(RunningStyleFrontRunner)
+	case first*3 <= fieldSize+2:
+		style.Scan(RunningStyleStalker)
+	case first*3 <= fieldSize*2+2:
+		style.Scan(RunningStyleCloser)
+	default:
+		style.Scan(RunningStyleDeepCloser)
+	}
+
+	return style
+}
+
+// fieldSize returns the number of the horses which started.
+func fieldSize(results []*Result) int {
+	n := 0
+
+	for i := 0; i < len(results); i++ {
+		if started(results[i].FinishStatus) {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,338 @@
+package parse
+
+import (
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+	"golang.org/x/xerrors"
+)
+
+const (
+	ProfileKindJockey  = "jockey"
+	ProfileKindTrainer = "trainer"
+	ProfileKindOwner   = "owner"
+	ProfileKindBreeder = "breeder"
+)
+
+const (
+	AffiliationMiho  = "美浦"
+	AffiliationRitto = "栗東"
+	AffiliationLocal = "地方"
+)
+
+// Profile is a row of the jockey, trainer or owner table. Owners have no kana,
+// affiliation, license year nor birth date on netkeiba.com.
+type Profile struct {
+	ID          string
+	Name        string
+	Kana        sql.NullString
+	Affiliation sql.NullString
+	LicenseYear sql.NullInt32
+	BirthDate   sql.NullString
+
+	// Warnings are the values which were not understood, and left empty.
+	Warnings []*Error
+}
+
+// ProfileStats is a row of the jockey_stats, trainer_stats, owner_stats or
+// breeder_stats table.
+type ProfileStats struct {
+	ID       string
+	Year     int
+	Rank     sql.NullInt32
+	First    int
+	Second   int
+	Third    int
+	Unplaced int
+	WinRate  sql.NullFloat64
+	Earnings sql.NullFloat64
+}
+
+// BuildProfileRecord builds the record from a jockey, trainer or owner page.
+func BuildProfileRecord(id string, doc *html.Node) (*Profile, error) {
+	record := &Profile{ID: id}
+
+	head := htmlquery.QuerySelector(doc, xpath.MustCompile(`//div[`+util.xpathContains("@class", "db_head_name")+`]`))
+	if head == nil {
+		return nil, xerrors.New(`Missing div[@class="db_head_name"] from HTML`)
+	}
+
+	h1 := htmlquery.QuerySelector(head, xpath.MustCompile(`//h1`))
+	if h1 == nil {
+		return nil, xerrors.New(`Missing profile name from HTML`)
+	}
+
+	// h1 looks like "武豊 (タケユタカ)", kana is omitted on owner pages
+	r1 := regexp.MustCompile(`^([^(（]+)[(（]?([^)）]*)[)）]?`)
+	if m := r1.FindStringSubmatch(strings.ReplaceAll(util.htmlInnerText(h1), "\u00a0", " ")); m != nil {
+		record.Name = strings.TrimSpace(m[1])
+		if kana := strings.TrimSpace(m[2]); kana != "" {
+			record.Kana.Scan(kana)
+		}
+	}
+
+	if record.Name == "" {
+		return nil, xerrors.New(`Missing profile name from HTML`)
+	}
+
+	affiliated := false
+
+	// p looks like "1969/03/15<br>栗東(フリー)"
+	if p := htmlquery.QuerySelector(head, xpath.MustCompile(`//p[`+util.xpathContains("@class", "txt_01")+`]`)); p != nil {
+		for _, line := range util.htmlSplitLineBreak(p) {
+			line = strings.TrimSpace(html.UnescapeString(line))
+
+			if t, err := time.Parse("2006/01/02", line); err == nil {
+				record.BirthDate.Scan(t.Format("2006-01-02"))
+				continue
+			}
+
+			if line != "" && !affiliated {
+				affiliated = true
+
+				if affiliation, err := determineAffiliation(line); err != nil {
+					record.Warnings = append(record.Warnings, &Error{Field: "affiliation", Err: err})
+				} else {
+					record.Affiliation.Scan(affiliation)
+				}
+			}
+		}
+	}
+
+	if td := htmlquery.QuerySelector(doc, xpath.MustCompile(`//th[contains(text(), '免許')]/following-sibling::td`)); td != nil {
+		r2 := regexp.MustCompile(`(\d{4})`)
+		if m := r2.FindStringSubmatch(util.htmlInnerText(td)); m != nil {
+			record.LicenseYear.Scan(util.atoi(m[1]))
+		}
+	}
+
+	return record, nil
+}
+
+// localRacecourses are the racecourses of the local (NAR) races, shown in
+// place of the training center on the pages of the local jockeys and trainers.
+var localRacecourses = []string{
+	"門別", "帯広", "盛岡", "水沢", "浦和", "船橋", "大井", "川崎",
+	"金沢", "笠松", "名古屋", "園田", "姫路", "高知", "佐賀", "荒尾", "福山",
+}
+
+// determineAffiliation returns the training center, or AffiliationLocal for
+// the local jockeys and trainers. The other affiliations such as those
+// abroad are reported.
+func determineAffiliation(s string) (string, error) {
+	switch {
+	case strings.Contains(s, AffiliationMiho):
+		return AffiliationMiho, nil
+	case strings.Contains(s, AffiliationRitto):
+		return AffiliationRitto, nil
+	case strings.Contains(s, AffiliationLocal):
+		return AffiliationLocal, nil
+	}
+
+	for i := 0; i < len(localRacecourses); i++ {
+		if strings.Contains(s, localRacecourses[i]) {
+			return AffiliationLocal, nil
+		}
+	}
+
+	return "", xerrors.Errorf("unexpected affiliation %q", s)
+}
+
+// BuildProfileStatsRecords builds the yearly stats records from a jockey,
+// trainer, owner or breeder page.
+func BuildProfileStatsRecords(id string, doc *html.Node) ([]*ProfileStats, error) {
+	tr := htmlquery.QuerySelectorAll(doc, xpath.MustCompile(`//table[`+util.xpathContains("@class", "race_table_01")+`]//tr`))
+
+	r := regexp.MustCompile(`^\d{4}$`)
+
+	var records []*ProfileStats
+
+	for i := 0; i < len(tr); i++ {
+		td := htmlquery.QuerySelectorAll(tr[i], xpath.MustCompile(`//td`))
+
+		// skip header rows and the "累計" row
+		if len(td) < 20 || !r.MatchString(util.htmlInnerText(td[0])) {
+			continue
+		}
+
+		record := &ProfileStats{
+			ID:       id,
+			Year:     util.htmlInnerTextAsInt(td[0]),
+			First:    util.htmlInnerTextAsInt(td[2]),
+			Second:   util.htmlInnerTextAsInt(td[3]),
+			Third:    util.htmlInnerTextAsInt(td[4]),
+			Unplaced: util.htmlInnerTextAsInt(td[5]),
+		}
+
+		if util.htmlInnerText(td[1]) != "" {
+			record.Rank.Scan(util.htmlInnerTextAsInt(td[1]))
+		}
+		if util.htmlInnerText(td[16]) != "" {
+			record.WinRate.Scan(util.htmlInnerTextAsFloat(td[16]))
+		}
+		if util.htmlInnerText(td[19]) != "" {
+			record.Earnings.Scan(util.htmlInnerTextAsFloat(td[19]))
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// Breeder is a row of the breeder table.
+type Breeder struct {
+	ID       string
+	Name     string
+	Location sql.NullString
+	Region   sql.NullString
+}
+
+// BuildBreederRecord builds the breeder record from a breeder page.
+func BuildBreederRecord(id string, doc *html.Node) (*Breeder, error) {
+	record := &Breeder{ID: id}
+
+	head := htmlquery.QuerySelector(doc, xpath.MustCompile(`//div[`+util.xpathContains("@class", "db_head_name")+`]`))
+	if head == nil {
+		return nil, xerrors.New(`Missing div[@class="db_head_name"] from HTML`)
+	}
+
+	if h1 := htmlquery.QuerySelector(head, xpath.MustCompile(`//h1`)); h1 != nil {
+		record.Name = strings.TrimSpace(strings.ReplaceAll(util.htmlInnerText(h1), "\u00a0", " "))
+	}
+
+	if record.Name == "" {
+		return nil, xerrors.New(`Missing breeder name from HTML`)
+	}
+
+	if p := htmlquery.QuerySelector(head, xpath.MustCompile(`//p[`+util.xpathContains("@class", "txt_01")+`]`)); p != nil {
+		if location := strings.TrimSpace(html.UnescapeString(util.htmlInnerTextFirstLine(p))); location != "" {
+			record.Location.Scan(location)
+
+			if region := determineRegion(location); region != "" {
+				record.Region.Scan(region)
+			}
+		}
+	}
+
+	return record, nil
+}
+
+// HorseProfile is the breeding data of a horse on its profile page.
+type HorseProfile struct {
+	ID         HorseID
+	Name       string
+	BreederID  sql.NullString
+	Breeder    sql.NullString
+	Birthplace sql.NullString
+	Region     sql.NullString
+}
+
+func (h *HorseProfile) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"id":         h.ID,
+		"name":       h.Name,
+		"breeder_id": util.nullable(h.BreederID),
+		"breeder":    util.nullable(h.Breeder),
+		"birthplace": util.nullable(h.Birthplace),
+		"region":     util.nullable(h.Region),
+	})
+}
+
+// BuildHorseProfileRecord builds the breeding data from a horse profile page.
+func BuildHorseProfileRecord(id string, doc *html.Node) (*HorseProfile, error) {
+	record := &HorseProfile{ID: HorseID(id)}
+
+	h1 := htmlquery.QuerySelector(doc, xpath.MustCompile(`//div[`+util.xpathContains("@class", "horse_title")+`]/h1`))
+	if h1 == nil {
+		return nil, xerrors.New(`Missing horse name from HTML`)
+	}
+	record.Name = util.htmlInnerText(h1)
+
+	table := htmlquery.QuerySelector(doc, xpath.MustCompile(`//table[`+util.xpathContains("@class", "db_prof_table")+`]`))
+	if table == nil {
+		return nil, xerrors.New(`Missing table[@class="db_prof_table"] from HTML`)
+	}
+
+	if td := htmlquery.QuerySelector(table, xpath.MustCompile(`//th[text()='生産者']/following-sibling::td`)); td != nil {
+		if breederID := util.htmlSelectHrefLastSegment(td); breederID != "" {
+			record.BreederID.Scan(breederID)
+		}
+		if breeder := util.htmlInnerText(td); breeder != "" {
+			record.Breeder.Scan(breeder)
+		}
+	}
+
+	if td := htmlquery.QuerySelector(table, xpath.MustCompile(`//th[text()='産地']/following-sibling::td`)); td != nil {
+		if birthplace := util.htmlInnerText(td); birthplace != "" {
+			record.Birthplace.Scan(birthplace)
+
+			if region := determineRegion(birthplace); region != "" {
+				record.Region.Scan(region)
+			}
+		}
+	}
+
+	return record, nil
+}
+
+// regions are the subprefectures of the major breeding areas of Hokkaido and
+// their municipalities.
+var regions = []struct {
+	name           string
+	municipalities []string
+}{
+	{"日高", []string{"日高町", "平取町", "新冠町", "新ひだか町", "静内町", "三石町", "浦河町", "様似町", "えりも町", "門別町"}},
+	{"胆振", []string{"苫小牧市", "白老町", "安平町", "早来町", "追分町", "厚真町", "むかわ町", "鵡川町", "穂別町", "伊達市", "洞爺湖町", "室蘭市", "登別市", "豊浦町", "壮瞥町"}},
+	{"十勝", []string{"帯広市", "音更町", "士幌町", "上士幌町", "鹿追町", "新得町", "清水町", "芽室町", "中札内村", "更別村", "大樹町", "広尾町", "幕別町", "池田町", "豊頃町", "本別町", "足寄町", "陸別町", "浦幌町"}},
+	{"石狩", []string{"札幌市", "江別市", "千歳市", "恵庭市", "北広島市", "石狩市", "当別町", "新篠津村"}},
+	{"空知", []string{"夕張市", "岩見沢市", "美唄市", "芦別市", "赤平市", "三笠市", "滝川市", "砂川市", "深川市", "栗山町", "由仁町", "長沼町", "新十津川町"}},
+	{"上川", []string{"旭川市", "士別市", "名寄市", "富良野市", "鷹栖町", "東神楽町", "当麻町", "比布町", "美瑛町", "上富良野町", "中富良野町", "南富良野町"}},
+	{"渡島", []string{"函館市", "北斗市", "七飯町", "森町", "八雲町", "長万部町"}},
+	{"釧路", []string{"釧路市", "釧路町", "厚岸町", "浜中町", "標茶町", "弟子屈町", "鶴居村", "白糠町"}},
+	{"根室", []string{"根室市", "別海町", "中標津町", "標津町", "羅臼町"}},
+	{"オホーツク", []string{"北見市", "網走市", "紋別市", "美幌町", "津別町", "斜里町", "清里町", "小清水町", "訓子府町", "置戸町", "佐呂間町", "遠軽町", "湧別町", "滝上町", "興部町", "西興部村", "雄武町", "大空町"}},
+}
+
+var prefecturePattern = regexp.MustCompile(`(北海道|東京都|大阪府|京都府|\p{Han}{2,3}県)`)
+
+// determineRegion returns the prefecture of the address, or the subprefecture
+// for Hokkaido. The birthplaces of the horses are the municipalities without
+// the prefecture for Hokkaido, so an address without a prefecture is looked
+// up in the municipalities of Hokkaido. Foreign countries such as "米" are
+// returned as they are.
+func determineRegion(s string) string {
+	prefecture := ""
+	if m := prefecturePattern.FindStringSubmatch(s); m != nil {
+		prefecture = m[1]
+	}
+
+	if prefecture != "" && prefecture != "北海道" {
+		return prefecture
+	}
+
+	// the longest name is matched, e.g. 小清水町 rather than 清水町
+	region, municipality := "", ""
+	for i := 0; i < len(regions); i++ {
+		for _, name := range regions[i].municipalities {
+			if len(municipality) < len(name) && strings.Contains(s, name) {
+				region, municipality = regions[i].name, name
+			}
+		}
+	}
+
+	switch {
+	case region != "":
+		return region
+	case prefecture != "":
+		return prefecture
+	default:
+		return s
+	}
+}
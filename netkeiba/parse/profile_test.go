@@ -0,0 +1,65 @@
+package parse
+
+import "testing"
+
+func TestDetermineRegion(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{s: "新ひだか町", want: "日高"},
+		{s: "北海道沙流郡日高町", want: "日高"},
+		{s: "安平町", want: "胆振"},
+		{s: "北海道河東郡音更町", want: "十勝"},
+		{s: "清水町", want: "十勝"},
+		{s: "小清水町", want: "オホーツク"},
+		{s: "北海道斜里郡小清水町", want: "オホーツク"},
+		{s: "中標津町", want: "根室"},
+		{s: "北海道", want: "北海道"},
+		{s: "北海道厚岸郡浜中町", want: "釧路"},
+		{s: "静岡県駿東郡清水町", want: "静岡県"},
+		{s: "長野県北安曇郡池田町", want: "長野県"},
+		{s: "和歌山県日高郡日高町", want: "和歌山県"},
+		{s: "静岡県周智郡森町", want: "静岡県"},
+		{s: "京都府相楽郡精華町", want: "京都府"},
+		{s: "鹿児島県", want: "鹿児島県"},
+		{s: "東京都", want: "東京都"},
+		{s: "米", want: "米"},
+	}
+
+	for _, tt := range tests {
+		if got := determineRegion(tt.s); got != tt.want {
+			t.Errorf("determineRegion(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestDetermineAffiliation(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+		ok   bool
+	}{
+		{s: "美浦", want: AffiliationMiho, ok: true},
+		{s: "栗東(フリー)", want: AffiliationRitto, ok: true},
+		{s: "地方", want: AffiliationLocal, ok: true},
+		{s: "大井", want: AffiliationLocal, ok: true},
+		{s: "(名古屋)", want: AffiliationLocal, ok: true},
+		{s: ""},
+		{s: "海外"},
+		{s: "香港"},
+		{s: "フリー"},
+	}
+
+	for _, tt := range tests {
+		got, err := determineAffiliation(tt.s)
+		if (err == nil) != tt.ok {
+			t.Errorf("determineAffiliation(%q) error = %v, want ok %v", tt.s, err, tt.ok)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("determineAffiliation(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,767 @@
+package parse
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/xerrors"
+)
+
+// RacePage is the records of a race result page.
+type RacePage struct {
+	Race    *Race     `json:"race"`
+	Payouts []*Payout `json:"payouts"`
+	Results []*Result `json:"results"`
+
+	// Classifications are the codes of the race by the classification
+	// schemes, which are configured by the caller and set by ClassifyRace.
+	Classifications []*RaceClassification `json:"-"`
+
+	// Warnings are the values which were not understood, and left empty.
+	Warnings []*Error `json:"-"`
+}
+
+// ReadRacePage parses the race result page read from r.
+func ReadRacePage(id RaceID, r io.Reader) (*RacePage, error) {
+	doc, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildRacePage(id, doc)
+}
+
+// BuildRacePage builds the race, payout and result records of the race.
+func BuildRacePage(id RaceID, doc *html.Node) (*RacePage, error) {
+	race, warnings, err := buildRaceRecord(id, doc)
+	if err != nil {
+		return nil, xerrors.Errorf("build race information record failure: %+w", err)
+	}
+
+	payouts, payoutWarnings, err := buildPayoutRecords(id, doc)
+	if err != nil {
+		return nil, xerrors.Errorf("build payout records failure: %+w", err)
+	}
+
+	results, resultWarnings, err := buildResultRecords(id, doc)
+	if err != nil {
+		return nil, xerrors.Errorf("build result records failure: %+w", err)
+	}
+
+	warnings = append(warnings, payoutWarnings...)
+	warnings = append(warnings, resultWarnings...)
+
+	race.FieldSize = fieldSize(results)
+
+	for i := 0; i < len(results); i++ {
+		results[i].NormalizedAge = normalizeAge(results[i].Age, race.Date)
+	}
+
+	return &RacePage{Race: race, Payouts: payouts, Results: results, Warnings: warnings}, nil
+}
+
+// Race is a row of the race table.
+type Race struct {
+	ID                 RaceID
+	Name               string
+	Course             string
+	Number             int
+	Surface            string
+	Direction          string
+	Distance           int
+	Weather            string
+	SurfaceState       string
+	SurfaceIndex       sql.NullInt32
+	Date               string
+	PostTime           string
+	Classification     string
+	ClassificationCode string
+
+	// TrackDirection, TrackLayout, Laps and ObstacleSurface are decoded from
+	// the Direction by parseTrack. ObstacleSurface is only of the
+	// steeplechases.
+	TrackDirection  sql.NullString
+	TrackLayout     sql.NullString
+	Laps            sql.NullInt32
+	ObstacleSurface sql.NullString
+
+	// Class and AgeCondition are the class and the age condition of the
+	// Classification, normalized to the class names since 2019 and the ages
+	// since 2001.
+	Class        sql.NullString
+	AgeCondition sql.NullString
+
+	// FieldSize is the number of the horses which started, and is counted
+	// from the results by BuildRacePage.
+	FieldSize int
+}
+
+// Payout is a row of the payout table.
+type Payout struct {
+	RaceID     RaceID
+	TicketType string
+	BetType    sql.NullString
+	Draw       string
+
+	// Split is the number of the dividend among those of the same draw,
+	// which is paid more than once in some dead heats.
+	Split      int
+	Amount     float64
+	Popularity int
+
+	// Numbers are the numbers of the draw, in the order of the finish if
+	// Ordered.
+	Numbers []int
+	Ordered bool
+}
+
+// Result is a row of the result table.
+type Result struct {
+	RaceID        RaceID
+	OrderOfFinish string
+
+	// FinishPosition is NULL when the horse did not finish. The demoted
+	// horses have the placing after the demotion, and an estimate of the
+	// placing they finished in as OriginalPlacing, counted from the finish
+	// times, which is NULL when the times do not tell it. The horses in a
+	// dead heat share the placing, and are flagged DeadHeat.
+	FinishPosition  sql.NullInt32
+	FinishStatus    sql.NullString
+	Demoted         bool
+	OriginalPlacing sql.NullInt32
+	DeadHeat        bool
+
+	Bracket int
+	Draw    int
+	HorseID HorseID
+	Horse   string
+	Sex     string
+	Age     int
+
+	// NormalizedAge is the Age counted since 2001, and is set from the date
+	// of the race by BuildRacePage.
+	NormalizedAge int
+
+	Weight        float64
+	JockeyID      JockeyID
+	Jockey        string
+	Time          sql.NullString
+	TimeSec       sql.NullFloat64
+	WinningMargin string
+
+	// MarginLengths is the winning margin in lengths, and LengthsBehind is
+	// the sum of them from the winner.
+	MarginLengths sql.NullFloat64
+	LengthsBehind sql.NullFloat64
+
+	SpeedIndex sql.NullInt32
+	Position   string
+
+	// Positions are the positions at the corners parsed from Position, and
+	// RunningStyle is labeled by the first of them.
+	Positions    []int
+	RunningStyle sql.NullString
+
+	SectionalTime sql.NullFloat64
+	Odds          float64
+	Popularity    int
+	HorseWeight   string
+
+	// BodyWeight, BodyWeightDelta and BodyWeightStatus are decoded from
+	// HorseWeight by ParseBodyWeight.
+	BodyWeight       sql.NullInt32
+	BodyWeightDelta  sql.NullInt32
+	BodyWeightStatus sql.NullString
+
+	Note      string
+	Stable    string
+	TrainerID TrainerID
+	OwnerID   OwnerID
+	Earnings  float64
+}
+
+func (r *Race) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"id":                  r.ID,
+		"name":                r.Name,
+		"course":              r.Course,
+		"number":              r.Number,
+		"surface":             r.Surface,
+		"direction":           r.Direction,
+		"track_direction":     util.nullable(r.TrackDirection),
+		"track_layout":        util.nullable(r.TrackLayout),
+		"laps":                util.nullable(r.Laps),
+		"obstacle_surface":    util.nullable(r.ObstacleSurface),
+		"distance":            r.Distance,
+		"weather":             r.Weather,
+		"surface_state":       r.SurfaceState,
+		"surface_index":       util.nullable(r.SurfaceIndex),
+		"date":                r.Date,
+		"post_time":           r.PostTime,
+		"classification":      r.Classification,
+		"classification_code": r.ClassificationCode,
+		"class":               util.nullable(r.Class),
+		"age_condition":       util.nullable(r.AgeCondition),
+		"field_size":          r.FieldSize,
+	})
+}
+
+func (p *Payout) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"race_id":     p.RaceID,
+		"ticket_type": p.TicketType,
+		"bet_type":    util.nullable(p.BetType),
+		"draw":        p.Draw,
+		"split":       p.Split,
+		"numbers":     p.Numbers,
+		"ordered":     p.Ordered,
+		"amount":      p.Amount,
+		"popularity":  p.Popularity,
+	})
+}
+
+func (r *Result) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"race_id":            r.RaceID,
+		"order_of_finish":    r.OrderOfFinish,
+		"finish_position":    util.nullable(r.FinishPosition),
+		"finish_status":      util.nullable(r.FinishStatus),
+		"demoted":            r.Demoted,
+		"original_placing":   util.nullable(r.OriginalPlacing),
+		"dead_heat":          r.DeadHeat,
+		"bracket":            r.Bracket,
+		"draw":               r.Draw,
+		"horse_id":           r.HorseID,
+		"horse":              r.Horse,
+		"sex":                r.Sex,
+		"age":                r.Age,
+		"normalized_age":     r.NormalizedAge,
+		"weight":             r.Weight,
+		"jockey_id":          r.JockeyID,
+		"jockey":             r.Jockey,
+		"time":               util.nullable(r.Time),
+		"time_sec":           util.nullable(r.TimeSec),
+		"winning_margin":     r.WinningMargin,
+		"margin_lengths":     util.nullable(r.MarginLengths),
+		"lengths_behind":     util.nullable(r.LengthsBehind),
+		"speed_index":        util.nullable(r.SpeedIndex),
+		"position":           r.Position,
+		"positions":          r.Positions,
+		"running_style":      util.nullable(r.RunningStyle),
+		"sectional_time":     util.nullable(r.SectionalTime),
+		"odds":               r.Odds,
+		"popularity":         r.Popularity,
+		"horse_weight":       r.HorseWeight,
+		"body_weight":        util.nullable(r.BodyWeight),
+		"body_weight_delta":  util.nullable(r.BodyWeightDelta),
+		"body_weight_status": util.nullable(r.BodyWeightStatus),
+		"note":               r.Note,
+		"stable":             r.Stable,
+		"trainer_id":         r.TrainerID,
+		"owner_id":           r.OwnerID,
+		"earnings":           r.Earnings,
+	})
+}
+
+// BuildRaceRecord builds the race record from the race result page.
+func BuildRaceRecord(id RaceID, doc *html.Node) (*Race, error) {
+	record, _, err := buildRaceRecord(id, doc)
+
+	return record, err
+}
+
+func buildRaceRecord(id RaceID, doc *html.Node) (*Race, []*Error, error) {
+	record := &Race{ID: id}
+
+	var warnings []*Error
+
+	raceData := htmlquery.QuerySelector(doc, xpath.MustCompile(`//dl[`+util.xpathContains("@class", "racedata")+`]`))
+	if raceData == nil {
+		return nil, nil, &Error{RaceID: id, Table: "race", Err: xerrors.New(`Missing race data from HTML`)}
+	}
+
+	if dt, err := htmlquery.Query(raceData, "//dt"); err == nil {
+		if i, err := strconv.Atoi(strings.TrimRight(util.htmlInnerText(dt), " R")); err == nil {
+			record.Number = i
+		}
+	} else {
+		return nil, nil, err
+	}
+
+	if h1, err := htmlquery.Query(raceData, "//h1"); err == nil {
+		record.Name = util.htmlInnerText(h1)
+	} else {
+		return nil, nil, err
+	}
+
+	if span, err := htmlquery.Query(raceData, "//span"); err == nil {
+		s := util.htmlInnerTextAndSplit(span, "/")
+		if len(s) < 4 {
+			return nil, nil, &Error{RaceID: id, Table: "race", Field: "racedata", Err: xerrors.Errorf("unexpected format %q", util.htmlInnerText(span))}
+		}
+
+		r1 := regexp.MustCompile(`([^\d]+(?:\d+周)?)([\d]+)m`)
+		m1 := r1.FindAllStringSubmatch(s[0], -1)
+		if m1 == nil {
+			return nil, nil, &Error{RaceID: id, Table: "race", Field: "distance", Err: xerrors.Errorf("unexpected format %q", s[0])}
+		}
+
+		record.Surface = string([]rune(m1[0][1])[:1])
+		record.Direction = string([]rune(m1[0][1])[1:])
+
+		if err := parseTrack(record); err != nil {
+			warnings = append(warnings, &Error{RaceID: id, Table: "race", Field: "direction", Err: err})
+		}
+
+		if i, err := strconv.Atoi(m1[0][2]); err == nil {
+			record.Distance = i
+		}
+
+		r2 := regexp.MustCompile(`.* \: (.+)`)
+
+		record.Weather = strings.Replace(strings.TrimSpace(s[1]), "天候 : ", "", -1)
+		record.SurfaceState = r2.ReplaceAllString(strings.TrimSpace(s[2]), "$1")
+		record.PostTime = strings.Replace(strings.TrimSpace(s[3]), "発走 : ", "", -1)
+	} else {
+		return nil, nil, err
+	}
+
+	if p := htmlquery.QuerySelector(doc, xpath.MustCompile(`//p[`+util.xpathContains("@class", "smalltxt")+`]`)); p != nil {
+		s := util.htmlInnerTextAndSplit(p, " ")
+		if len(s) < 3 {
+			return nil, nil, &Error{RaceID: id, Table: "race", Field: "smalltxt", Err: xerrors.Errorf("unexpected format %q", util.htmlInnerText(p))}
+		}
+
+		t, _ := time.Parse("2006年1月2日", s[0])
+
+		r := regexp.MustCompile(`.*(中京|中山|京都|函館|小倉|新潟|札幌|東京|福島|阪神).*`)
+
+		record.Course = r.ReplaceAllString(s[1], "$1")
+		record.Date = t.Format("2006-01-02")
+		record.Classification = s[2]
+		record.Class = normalizeClass(record.Classification)
+		record.AgeCondition = normalizeAgeCondition(record.Classification, record.Date)
+		record.ClassificationCode = DetermineClassificationCode(record)
+	} else {
+		return nil, nil, &Error{RaceID: id, Table: "race", Err: xerrors.New(`Missing p[@class="smalltxt"]`)}
+	}
+
+	if td := htmlquery.QuerySelector(doc, xpath.MustCompile(`//table[@summary="馬場情報"]/tbody/tr/th[text()='馬場指数']/following-sibling::td`)); td != nil {
+		// td looks like "-12&nbsp;..."
+		if f := strings.Fields(util.htmlInnerText(td)); 0 < len(f) {
+			if i, err := strconv.Atoi(f[0]); err == nil {
+				record.SurfaceIndex.Scan(i)
+			}
+		}
+	}
+
+	return record, warnings, nil
+}
+
+// BuildPayoutRecords builds the payout records from the race result page.
+func BuildPayoutRecords(id RaceID, doc *html.Node) ([]*Payout, error) {
+	records, _, err := buildPayoutRecords(id, doc)
+
+	return records, err
+}
+
+func buildPayoutRecords(id RaceID, doc *html.Node) ([]*Payout, []*Error, error) {
+	tr := htmlquery.QuerySelectorAll(doc, xpath.MustCompile(`//table[`+util.xpathContains("@class", "pay_table_01")+`]//tr`))
+	if len(tr) == 0 {
+		return nil, nil, &Error{RaceID: id, Table: "payout", Err: xerrors.New(`Missing payout table from HTML`)}
+	}
+
+	var records []*Payout
+	var warnings []*Error
+
+	// the number of the dividends of each ticket type and draw so far
+	splits := make(map[[2]string]int)
+
+	for i := 0; i < len(tr); i++ {
+		th := htmlquery.QuerySelector(tr[i], xpath.MustCompile(`//th`))
+		td := htmlquery.QuerySelectorAll(tr[i], xpath.MustCompile(`//td`))
+		if th == nil || len(td) < 3 {
+			return nil, nil, &Error{RaceID: id, Table: "payout", Row: i + 1, Err: xerrors.Errorf("%d cells, expected 3", len(td))}
+		}
+
+		ticketType := util.htmlInnerText(th)
+		draw := util.htmlSplitLineBreak(td[0])
+		amount := util.htmlSplitLineBreak(td[1])
+		popularity := util.htmlSplitLineBreak(td[2])
+
+		if len(amount) < len(draw) || len(popularity) < len(draw) {
+			return nil, nil, &Error{RaceID: id, Table: "payout", Row: i + 1, Err: xerrors.Errorf("%d draws, but %d amounts and %d popularities", len(draw), len(amount), len(popularity))}
+		}
+
+		betType, ok := betTypes[ticketType]
+		if !ok {
+			warnings = append(warnings, &Error{RaceID: id, Table: "payout", Row: i + 1, Field: "券種", Err: xerrors.Errorf("unexpected ticket type %q", ticketType)})
+		}
+
+		for j := 0; j < len(draw); j++ {
+			key := [2]string{ticketType, draw[j]}
+			splits[key]++
+
+			record := &Payout{
+				RaceID:     id,
+				TicketType: ticketType,
+				Draw:       draw[j],
+				Split:      splits[key],
+				Amount:     util.parseFloat(amount[j]),
+				Popularity: util.atoi(popularity[j]),
+				Ordered:    isOrdered(betType),
+			}
+
+			if ok {
+				record.BetType.Scan(betType)
+			}
+
+			if numbers, err := parseDraw(draw[j]); err != nil {
+				warnings = append(warnings, &Error{RaceID: id, Table: "payout", Row: i + 1, Field: "組番", Err: err})
+			} else {
+				record.Numbers = numbers
+			}
+
+			records = append(records, record)
+		}
+	}
+
+	return records, warnings, nil
+}
+
+// resultColumn is a column of the result table.
+type resultColumn int
+
+const (
+	resultColumnOrderOfFinish resultColumn = iota
+	resultColumnBracket
+	resultColumnDraw
+	resultColumnHorse
+	resultColumnSexAge
+	resultColumnWeight
+	resultColumnJockey
+	resultColumnTime
+	resultColumnWinningMargin
+	resultColumnSpeedIndex
+	resultColumnPosition
+	resultColumnSectionalTime
+	resultColumnOdds
+	resultColumnPopularity
+	resultColumnHorseWeight
+	resultColumnWorkoutTime
+	resultColumnStableComment
+	resultColumnNote
+	resultColumnTrainer
+	resultColumnOwner
+	resultColumnEarnings
+)
+
+// resultColumns maps the header labels of the result table, normalized by
+// normalizeHeaderLabel, to the columns. The premium columns such as the speed
+// index are shown as "**" when logged out.
+var resultColumns = map[string]resultColumn{
+	"着順":     resultColumnOrderOfFinish,
+	"枠番":     resultColumnBracket,
+	"馬番":     resultColumnDraw,
+	"馬名":     resultColumnHorse,
+	"性齢":     resultColumnSexAge,
+	"斤量":     resultColumnWeight,
+	"騎手":     resultColumnJockey,
+	"タイム":    resultColumnTime,
+	"着差":     resultColumnWinningMargin,
+	"タイム指数":  resultColumnSpeedIndex,
+	"通過":     resultColumnPosition,
+	"上り":     resultColumnSectionalTime,
+	"単勝":     resultColumnOdds,
+	"人気":     resultColumnPopularity,
+	"馬体重":    resultColumnHorseWeight,
+	"調教タイム":  resultColumnWorkoutTime,
+	"厩舎コメント": resultColumnStableComment,
+	"備考":     resultColumnNote,
+	"調教師":    resultColumnTrainer,
+	"馬主":     resultColumnOwner,
+	"賞金(万円)": resultColumnEarnings,
+}
+
+// requiredResultColumns are the columns shown whether logged in or not.
+var requiredResultColumns = []resultColumn{
+	resultColumnOrderOfFinish,
+	resultColumnBracket,
+	resultColumnDraw,
+	resultColumnHorse,
+	resultColumnSexAge,
+	resultColumnWeight,
+	resultColumnJockey,
+	resultColumnTime,
+	resultColumnWinningMargin,
+	resultColumnOdds,
+	resultColumnPopularity,
+	resultColumnHorseWeight,
+	resultColumnTrainer,
+}
+
+// normalizeHeaderLabel folds the half-width katakana and the full-width
+// parentheses of the header label, and removes the spaces and line breaks.
+func normalizeHeaderLabel(s string) string {
+	return strings.Join(strings.Fields(norm.NFKC.String(s)), "")
+}
+
+// resultRow is a row of the result table, whose cells are looked up by the
+// columns read from the header.
+type resultRow struct {
+	raceID   RaceID
+	index    int
+	td       []*html.Node
+	columns  map[resultColumn]int
+	warnings []*Error
+}
+
+func (r *resultRow) cell(column resultColumn) *html.Node {
+	if i, ok := r.columns[column]; ok {
+		return r.td[i]
+	}
+	return nil
+}
+
+func (r *resultRow) text(column resultColumn) string {
+	if n := r.cell(column); n != nil {
+		return util.htmlInnerText(n)
+	}
+	return ""
+}
+
+// int returns zero for the empty cells and the placeholders such as "**",
+// and warns on the other values which are not numbers.
+func (r *resultRow) int(column resultColumn) int {
+	t := r.text(column)
+
+	i, err := strconv.Atoi(t)
+	if err != nil && !isResultPlaceholder(t) {
+		r.warn(column, err)
+	}
+
+	return i
+}
+
+func (r *resultRow) float(column resultColumn) float64 {
+	t := strings.ReplaceAll(r.text(column), ",", "")
+
+	f, err := strconv.ParseFloat(t, 64)
+	if err != nil && !isResultPlaceholder(t) {
+		r.warn(column, err)
+	}
+
+	return f
+}
+
+func (r *resultRow) warn(column resultColumn, err error) {
+	r.warnings = append(r.warnings, &Error{RaceID: r.raceID, Table: "result", Row: r.index, Field: resultColumnLabel(column), Err: err})
+}
+
+func isResultPlaceholder(s string) bool {
+	return s == "" || strings.Trim(s, "*-") == ""
+}
+
+func resultColumnLabel(column resultColumn) string {
+	for label, c := range resultColumns {
+		if c == column {
+			return label
+		}
+	}
+	return ""
+}
+
+func (r *resultRow) hrefLastSegment(column resultColumn) string {
+	return util.htmlSelectHrefLastSegment(r.cell(column))
+}
+
+// readResultHeader maps the columns of the result table to the indices of
+// the cells. It fails on unknown or duplicate labels and missing required
+// columns, so that a change of the table never shifts the values into wrong
+// fields.
+func readResultHeader(id RaceID, tr *html.Node) (map[resultColumn]int, error) {
+	th := htmlquery.QuerySelectorAll(tr, xpath.MustCompile(`//th`))
+
+	columns := make(map[resultColumn]int, len(th))
+
+	for i := 0; i < len(th); i++ {
+		label := normalizeHeaderLabel(htmlquery.InnerText(th[i]))
+
+		column, ok := resultColumns[label]
+		if !ok {
+			return nil, &Error{RaceID: id, Table: "result", Field: label, Err: xerrors.New("unknown header")}
+		}
+		if _, ok := columns[column]; ok {
+			return nil, &Error{RaceID: id, Table: "result", Field: label, Err: xerrors.New("duplicate header")}
+		}
+
+		columns[column] = i
+	}
+
+	for i := 0; i < len(requiredResultColumns); i++ {
+		if _, ok := columns[requiredResultColumns[i]]; !ok {
+			return nil, &Error{RaceID: id, Table: "result", Field: resultColumnLabel(requiredResultColumns[i]), Err: xerrors.New("missing header")}
+		}
+	}
+
+	return columns, nil
+}
+
+// BuildResultRecords builds the result records from the race result page.
+func BuildResultRecords(id RaceID, doc *html.Node) ([]*Result, error) {
+	records, _, err := buildResultRecords(id, doc)
+
+	return records, err
+}
+
+func buildResultRecords(id RaceID, doc *html.Node) ([]*Result, []*Error, error) {
+	tr := htmlquery.QuerySelectorAll(doc, xpath.MustCompile(`//table[`+util.xpathContains("@class", "race_table_01")+`]//tr`))
+
+	// first line is table header
+	if len(tr) < 2 {
+		return nil, nil, &Error{RaceID: id, Table: "result", Err: xerrors.New(`race result not found, or is invalid`)}
+	}
+
+	columns, err := readResultHeader(id, tr[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records []*Result
+	var warnings []*Error
+
+	for i := 1; i < len(tr); i++ {
+		td := htmlquery.QuerySelectorAll(tr[i], xpath.MustCompile(`//td`))
+
+		if len(td) != len(columns) {
+			return nil, nil, &Error{RaceID: id, Table: "result", Row: i, Err: xerrors.Errorf("%d cells, but header has %d", len(td), len(columns))}
+		}
+
+		row := &resultRow{raceID: id, index: i, td: td, columns: columns}
+
+		sexAge := []rune(row.text(resultColumnSexAge))
+		stable := []rune(row.text(resultColumnTrainer))
+
+		record := &Result{
+			RaceID:        id,
+			OrderOfFinish: row.text(resultColumnOrderOfFinish),
+			Bracket:       row.int(resultColumnBracket),
+			Draw:          row.int(resultColumnDraw),
+			Horse:         row.text(resultColumnHorse),
+			Weight:        row.float(resultColumnWeight),
+			Jockey:        row.text(resultColumnJockey),
+			WinningMargin: row.text(resultColumnWinningMargin),
+			Position:      row.text(resultColumnPosition),
+			Odds:          row.float(resultColumnOdds),
+			Popularity:    row.int(resultColumnPopularity),
+			HorseWeight:   row.text(resultColumnHorseWeight),
+			Note:          row.text(resultColumnNote),
+			Earnings:      row.float(resultColumnEarnings),
+		}
+
+		// the horse ID is part of the key of the result, so the page fails
+		// without it. The other IDs are left empty when the links are missing
+		horseID, err := ParseHorseID(row.hrefLastSegment(resultColumnHorse))
+		if err != nil {
+			return nil, nil, &Error{RaceID: id, Table: "result", Row: i, Field: resultColumnLabel(resultColumnHorse), Err: err}
+		}
+		record.HorseID = horseID
+
+		if id, err := ParseJockeyID(row.hrefLastSegment(resultColumnJockey)); err == nil {
+			record.JockeyID = id
+		} else {
+			row.warn(resultColumnJockey, err)
+		}
+		if id, err := ParseTrainerID(row.hrefLastSegment(resultColumnTrainer)); err == nil {
+			record.TrainerID = id
+		} else {
+			row.warn(resultColumnTrainer, err)
+		}
+		// owner is not shown when logged out
+		if row.cell(resultColumnOwner) != nil {
+			if id, err := ParseOwnerID(row.hrefLastSegment(resultColumnOwner)); err == nil {
+				record.OwnerID = id
+			} else {
+				row.warn(resultColumnOwner, err)
+			}
+		}
+
+		if f, err := decodeOrderOfFinish(record.OrderOfFinish); err == nil {
+			record.FinishPosition = f.position
+			record.FinishStatus.Scan(f.status)
+			record.Demoted = f.demoted
+		} else {
+			row.warn(resultColumnOrderOfFinish, err)
+		}
+
+		if p, err := parsePositions(record.Position); err == nil {
+			record.Positions = p
+		} else {
+			row.warn(resultColumnPosition, err)
+		}
+
+		if w, err := ParseBodyWeight(record.HorseWeight); err == nil {
+			record.BodyWeight = w.Weight
+			record.BodyWeightDelta = w.Delta
+			record.BodyWeightStatus = w.Status
+		} else {
+			row.warn(resultColumnHorseWeight, err)
+		}
+
+		if 0 < len(sexAge) {
+			record.Sex = string(sexAge[:1])
+			record.Age, _ = strconv.Atoi(string(sexAge[1:]))
+		}
+
+		// trainer looks like "[東]藤沢和雄"
+		if 1 < len(stable) {
+			record.Stable = string(stable[1:2])
+		}
+
+		if t := row.text(resultColumnTime); t != "" {
+			record.Time.Scan(t)
+			if sec, err := util.parseFinishTime(t); err == nil {
+				record.TimeSec.Scan(sec)
+			} else {
+				row.warn(resultColumnTime, err)
+			}
+		}
+		if t := row.text(resultColumnSectionalTime); t != "" {
+			record.SectionalTime.Scan(row.float(resultColumnSectionalTime))
+		}
+		// speed index is "**" when logged out
+		if i, err := strconv.Atoi(row.text(resultColumnSpeedIndex)); err == nil {
+			record.SpeedIndex.Scan(i)
+		}
+
+		records = append(records, record)
+		warnings = append(warnings, row.warnings...)
+	}
+
+	n := fieldSize(records)
+
+	for i := 0; i < len(records); i++ {
+		if 0 < len(records[i].Positions) {
+			records[i].RunningStyle = determineRunningStyle(records[i].Positions[0], n)
+		}
+	}
+
+	countLengthsBehind(records, func(i int, err error) {
+		warnings = append(warnings, &Error{RaceID: id, Table: "result", Row: i + 1, Field: resultColumnLabel(resultColumnWinningMargin), Err: err})
+	})
+
+	estimateOriginalPlacings(records)
+
+	markDeadHeats(records)
+
+	return records, warnings, nil
+}
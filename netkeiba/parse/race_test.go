@@ -0,0 +1,127 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/xerrors"
+)
+
+// resultCells are the header labels and the cells of a runner of the result
+// table, in the order of the page.
+var resultCells = [][2]string{
+	{"着<br />順", `1`},
+	{"枠<br />番", `<span>2</span>`},
+	{"馬<br />番", `3`},
+	{"馬名", `<a href="/horse/2017105318/">サンプルホースイチ</a>`},
+	{"性齢", `牡4`},
+	{"斤量", `57`},
+	{"騎手", `<a href="/jockey/01126/">騎手イチ</a>`},
+	{"タイム", `1:57.9`},
+	{"着差", ``},
+	{"ﾀｲﾑ<br />指数", `112`},
+	{"通過", `3-3-2`},
+	{"上り", `33.6`},
+	{"単勝", `3.8`},
+	{"人<br />気", `2`},
+	{"馬体重", `486(+4)`},
+	{"調教<br />ﾀｲﾑ", ``},
+	{"厩舎<br />ｺﾒﾝﾄ", ``},
+	{"備考", ``},
+	{"調教師", `[東] <a href="/trainer/01061/">調教師イチ</a>`},
+	{"馬主", `<a href="/owner/226800/">馬主イチ</a>`},
+	{"賞金<br />(万円)", `6,738.2`},
+}
+
+// resultTable returns the result table of the cells whose labels are not
+// omitted, in the order of the indices.
+func resultTable(order []int, omit ...string) string {
+	var th, td strings.Builder
+
+	for i := 0; i < len(order); i++ {
+		cell := resultCells[order[i]]
+
+		omitted := false
+		for j := 0; j < len(omit); j++ {
+			if cell[0] == omit[j] {
+				omitted = true
+			}
+		}
+		if omitted {
+			continue
+		}
+
+		th.WriteString("<th>" + cell[0] + "</th>")
+		td.WriteString("<td>" + cell[1] + "</td>")
+	}
+
+	return `<table class="race_table_01"><tr>` + th.String() + `</tr><tr>` + td.String() + `</tr></table>`
+}
+
+func resultOrder(swaps ...[2]int) []int {
+	order := make([]int, len(resultCells))
+	for i := 0; i < len(order); i++ {
+		order[i] = i
+	}
+
+	for i := 0; i < len(swaps); i++ {
+		order[swaps[i][0]], order[swaps[i][1]] = order[swaps[i][1]], order[swaps[i][0]]
+	}
+
+	return order
+}
+
+func TestBuildResultRecordsHeader(t *testing.T) {
+	tests := []struct {
+		name  string
+		table string
+		owner string
+		err   string
+	}{
+		{name: "page order", table: resultTable(resultOrder()), owner: "226800"},
+		{name: "reordered", table: resultTable(resultOrder([2]int{3, 6}, [2]int{12, 13}, [2]int{18, 19})), owner: "226800"},
+		{name: "optional columns missing", table: resultTable(resultOrder(), "ﾀｲﾑ<br />指数", "調教<br />ﾀｲﾑ", "厩舎<br />ｺﾒﾝﾄ", "備考", "馬主")},
+		{name: "unknown", table: strings.Replace(resultTable(resultOrder()), "<th>備考</th>", "<th>メモ</th>", 1), err: "unknown header"},
+		{name: "duplicate", table: strings.Replace(resultTable(resultOrder()), "<th>備考</th>", "<th>馬名</th>", 1), err: "duplicate header"},
+		{name: "required column missing", table: resultTable(resultOrder(), "騎手"), err: "missing header"},
+		{name: "horse link missing", table: strings.Replace(resultTable(resultOrder()), `<a href="/horse/2017105318/">サンプルホースイチ</a>`, "サンプルホースイチ", 1), err: "row 1: field 馬名"},
+		{name: "cells missing", table: strings.Replace(resultTable(resultOrder()), "<td>3.8</td>", "", 1), err: "20 cells, but header has 21"},
+	}
+
+	for _, tt := range tests {
+		doc, err := Parse(strings.NewReader(tt.table))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		records, warnings, err := buildResultRecords(202105021211, doc)
+
+		if tt.err != "" {
+			var e *Error
+			if err == nil || !xerrors.As(err, &e) || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: error = %v", tt.name, err)
+			continue
+		}
+		if 0 < len(warnings) {
+			t.Errorf("%s: warnings = %v", tt.name, warnings)
+		}
+
+		r := records[0]
+
+		if r.HorseID != "2017105318" || r.Horse != "サンプルホースイチ" || r.JockeyID != "01126" || r.Jockey != "騎手イチ" {
+			t.Errorf("%s: horse %s %s, jockey %s %s", tt.name, r.HorseID, r.Horse, r.JockeyID, r.Jockey)
+		}
+		if r.Odds != 3.8 || r.Popularity != 2 || r.TrainerID != "01061" || r.Stable != "東" || r.Earnings != 6738.2 {
+			t.Errorf("%s: odds %v, popularity %d, trainer %s %s, earnings %v", tt.name, r.Odds, r.Popularity, r.TrainerID, r.Stable, r.Earnings)
+		}
+		if string(r.OwnerID) != tt.owner {
+			t.Errorf("%s: owner %q, want %q", tt.name, r.OwnerID, tt.owner)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package parse
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	TrackDirectionRight    = "RIGHT"
+	TrackDirectionLeft     = "LEFT"
+	TrackDirectionStraight = "STRAIGHT"
+
+	TrackLayoutInner        = "INNER"
+	TrackLayoutOuter        = "OUTER"
+	TrackLayoutOuterToInner = "OUTER_TO_INNER"
+	TrackLayoutInnerToOuter = "INNER_TO_OUTER"
+
+	ObstacleSurfaceTurf     = "TURF"
+	ObstacleSurfaceDirt     = "DIRT"
+	ObstacleSurfaceTurfDirt = "TURF_DIRT"
+)
+
+var trackDirections = map[string]string{
+	"右":  TrackDirectionRight,
+	"左":  TrackDirectionLeft,
+	"直線": TrackDirectionStraight,
+}
+
+var trackLayouts = map[string]string{
+	"内":   TrackLayoutInner,
+	"外":   TrackLayoutOuter,
+	"外-内": TrackLayoutOuterToInner,
+	"内-外": TrackLayoutInnerToOuter,
+}
+
+// trackPattern matches the direction of the course following the surface,
+// such as "右", "左 外", "直線", "右 内2周" or, for the steeplechases, the
+// surfaces of the course and the layout such as "芝 外-内" or "芝ダート".
+var trackPattern = regexp.MustCompile(`^((?:芝|ダート|ダ|→)*)(右|左|直線)?\s*(外-内|内-外|外|内)?(?:(\d+)周)?$`)
+
+// parseTrack decodes the Direction of the race, the text between the surface
+// and the distance, into the TrackDirection, the TrackLayout, the Laps and
+// the ObstacleSurface.
+func parseTrack(race *Race) error {
+	m := trackPattern.FindStringSubmatch(strings.TrimSpace(race.Direction))
+	if m == nil || (m[1] != "" && race.Surface != "障") {
+		return xerrors.Errorf("unexpected direction %q", race.Direction)
+	}
+
+	if direction, ok := trackDirections[m[2]]; ok {
+		race.TrackDirection.Scan(direction)
+	}
+
+	if layout, ok := trackLayouts[m[3]]; ok {
+		race.TrackLayout.Scan(layout)
+	}
+
+	if i, err := strconv.Atoi(m[4]); err == nil {
+		race.Laps.Scan(i)
+	}
+
+	turf, dirt := strings.Contains(m[1], "芝"), strings.Contains(m[1], "ダ")
+
+	switch {
+	case turf && dirt:
+		race.ObstacleSurface.Scan(ObstacleSurfaceTurfDirt)
+	case turf:
+		race.ObstacleSurface.Scan(ObstacleSurfaceTurf)
+	case dirt:
+		race.ObstacleSurface.Scan(ObstacleSurfaceDirt)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package parse
+
+import "testing"
+
+func TestParseTrack(t *testing.T) {
+	tests := []struct {
+		surface         string
+		direction       string
+		trackDirection  string
+		trackLayout     string
+		laps            int32
+		obstacleSurface string
+		ok              bool
+	}{
+		{surface: "芝", direction: "右", trackDirection: TrackDirectionRight, ok: true},
+		{surface: "ダ", direction: "左", trackDirection: TrackDirectionLeft, ok: true},
+		{surface: "芝", direction: "直線", trackDirection: TrackDirectionStraight, ok: true},
+		{surface: "芝", direction: "右 外", trackDirection: TrackDirectionRight, trackLayout: TrackLayoutOuter, ok: true},
+		{surface: "芝", direction: "左 内", trackDirection: TrackDirectionLeft, trackLayout: TrackLayoutInner, ok: true},
+		{surface: "芝", direction: "右 外-内", trackDirection: TrackDirectionRight, trackLayout: TrackLayoutOuterToInner, ok: true},
+		{surface: "芝", direction: "右 内2周", trackDirection: TrackDirectionRight, trackLayout: TrackLayoutInner, laps: 2, ok: true},
+		{surface: "障", direction: "芝", obstacleSurface: ObstacleSurfaceTurf, ok: true},
+		{surface: "障", direction: "芝 外-内", trackLayout: TrackLayoutOuterToInner, obstacleSurface: ObstacleSurfaceTurf, ok: true},
+		{surface: "障", direction: "芝 内-外", trackLayout: TrackLayoutInnerToOuter, obstacleSurface: ObstacleSurfaceTurf, ok: true},
+		{surface: "障", direction: "芝→ダート", obstacleSurface: ObstacleSurfaceTurfDirt, ok: true},
+		{surface: "障", direction: "ダート", obstacleSurface: ObstacleSurfaceDirt, ok: true},
+		{surface: "芝", direction: "", ok: true},
+		{surface: "芝", direction: "ダート右"},
+		{surface: "芝", direction: "右回り"},
+	}
+
+	for _, tt := range tests {
+		race := &Race{Surface: tt.surface, Direction: tt.direction}
+
+		err := parseTrack(race)
+		if (err == nil) != tt.ok {
+			t.Errorf("parseTrack(%q, %q) error = %v, want ok %v", tt.surface, tt.direction, err, tt.ok)
+			continue
+		}
+
+		if race.TrackDirection.String != tt.trackDirection || race.TrackLayout.String != tt.trackLayout || race.Laps.Int32 != tt.laps || race.ObstacleSurface.String != tt.obstacleSurface {
+			t.Errorf("parseTrack(%q, %q) = %q %q %d %q, want %q %q %d %q", tt.surface, tt.direction,
+				race.TrackDirection.String, race.TrackLayout.String, race.Laps.Int32, race.ObstacleSurface.String,
+				tt.trackDirection, tt.trackLayout, tt.laps, tt.obstacleSurface)
+		}
+	}
+}
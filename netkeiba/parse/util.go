@@ -0,0 +1,130 @@
+package parse
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+	"golang.org/x/xerrors"
+)
+
+var util = utility{}
+
+// utility groups the helpers of the parsers, which are called as util.xxx.
+type utility struct{}
+
+func (u utility) xpathContains(attr string, value string) string {
+	return `contains(concat(' ', ` + attr + `, ' '), ' ` + value + ` ')`
+}
+
+func (u utility) htmlInnerText(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	return strings.TrimSpace(htmlquery.InnerText(n))
+}
+
+func (u utility) htmlInnerTextAsInt(n *html.Node) int {
+	return u.atoi(u.htmlInnerText(n))
+}
+
+func (u utility) htmlInnerTextAsFloat(n *html.Node) float64 {
+	return u.parseFloat(u.htmlInnerText(n))
+}
+
+func (u utility) htmlAnchorHref(n *html.Node) string {
+	if n != nil {
+		if a := htmlquery.QuerySelector(n, xpath.MustCompile(`//a`)); a != nil {
+			return htmlquery.SelectAttr(a, "href")
+		}
+	}
+	return ""
+}
+
+func (u utility) htmlSelectHrefLastSegment(n *html.Node) string {
+	href := u.htmlAnchorHref(n)
+
+	if href != "" {
+		href = strings.TrimRight(href, "/")
+		return href[strings.LastIndex(href, "/")+1:]
+	}
+
+	return ""
+}
+
+func (u utility) htmlInnerTextAndSplit(n *html.Node, sep string) []string {
+	return strings.Split(u.htmlInnerText(n), sep)
+}
+
+func (u utility) htmlInnerTextFirstLine(n *html.Node) string {
+	if n != nil {
+		if v := u.htmlSplitLineBreak(n); 0 < len(v) {
+			return strings.TrimSpace(v[0])
+		}
+	}
+	return ""
+}
+
+func (u utility) htmlInnerTextFirstRow(n *html.Node) string {
+	if v := u.htmlInnerTextAndSplit(n, " "); 0 < len(v) {
+		return strings.TrimSpace(v[0])
+	}
+	return ""
+}
+
+func (u utility) htmlSplitLineBreak(n *html.Node) []string {
+	if n == nil {
+		return nil
+	}
+	r := regexp.MustCompile(`<br\s*/?>`)
+	return r.Split(htmlquery.OutputHTML(n, false), -1)
+}
+
+func (u utility) atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+
+	return i
+}
+
+func (u utility) parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+
+	return f
+}
+
+func (u utility) parseFinishTime(s string) (float64, error) {
+	// s looks like "1:23.4"
+	ss := strings.Split(s, ":")
+	if len(ss) != 2 {
+		return 0, xerrors.Errorf("unexpected finish time %q", s)
+	}
+
+	sec, err := strconv.ParseFloat(ss[1], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if ss[0] == "0" {
+		return sec, nil
+	}
+
+	min, err := strconv.ParseFloat(ss[0], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return (min * 60.0) + sec, nil
+}
+
+// nullable returns nil for NULL so that sql.Null* values are encoded to
+// JSON as null instead of an object.
+func (u utility) nullable(v driver.Valuer) interface{} {
+	if val, err := v.Value(); err == nil {
+		return val
+	}
+	return nil
+}
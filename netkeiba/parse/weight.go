@@ -0,0 +1,70 @@
+package parse
+
+import (
+	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	BodyWeightMeasured           = "measured"
+	BodyWeightUnmeasured         = "unmeasured"
+	BodyWeightPreviousUnmeasured = "previous_unmeasured"
+	BodyWeightFirstStart         = "first_start"
+)
+
+// BodyWeight is the horse weight decoded. Delta is NULL unless the weight of
+// the previous start was measured.
+type BodyWeight struct {
+	Weight sql.NullInt32
+	Delta  sql.NullInt32
+	Status sql.NullString
+}
+
+// ParseBodyWeight parses the horse weight, which looks like "480(+4)",
+// "480(0)", "480(前計不)" when the previous weight was not measured, "480" on
+// the first start, or "計不" when it was not measured. The empty weight of the
+// scratched horses has no status.
+func ParseBodyWeight(s string) (*BodyWeight, error) {
+	w := &BodyWeight{}
+
+	switch s = strings.TrimSpace(s); s {
+	case "":
+		return w, nil
+	case "計不":
+		w.Status.Scan(BodyWeightUnmeasured)
+		return w, nil
+	}
+
+	r := regexp.MustCompile(`^(\d+)(?:\(([+-]?\d+|前計不)\))?$`)
+
+	m := r.FindStringSubmatch(s)
+	if m == nil {
+		return nil, xerrors.Errorf("unexpected horse weight %q", s)
+	}
+
+	i, err := strconv.Atoi(m[1])
+	if err != nil {
+		return nil, err
+	}
+	w.Weight.Scan(i)
+
+	switch m[2] {
+	case "":
+		w.Status.Scan(BodyWeightFirstStart)
+	case "前計不":
+		w.Status.Scan(BodyWeightPreviousUnmeasured)
+	default:
+		d, err := strconv.Atoi(m[2])
+		if err != nil {
+			return nil, err
+		}
+		w.Delta.Scan(d)
+		w.Status.Scan(BodyWeightMeasured)
+	}
+
+	return w, nil
+}
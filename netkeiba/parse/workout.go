@@ -0,0 +1,169 @@
+package parse
+
+import (
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+	"golang.org/x/xerrors"
+)
+
+const (
+	WorkoutCourseHill  = "坂路"
+	WorkoutCourseWood  = "ウッド"
+	WorkoutCoursePoly  = "ポリ"
+	WorkoutCourseTurf  = "芝"
+	WorkoutCourseDirt  = "ダート"
+	WorkoutCoursePool  = "プール"
+	WorkoutCourseOther = "その他"
+)
+
+// Workout is a row of the workout table.
+type Workout struct {
+	HorseID      HorseID
+	Date         string
+	RaceID       RaceID
+	Course       string
+	CourseType   string
+	SurfaceState sql.NullString
+	Rider        sql.NullString
+	Intensity    sql.NullString
+	Splits       []*WorkoutSplit
+}
+
+// WorkoutSplit is the time from the furlong pole to the finish, e.g. the
+// split of furlong 3 is the last 3 furlongs time.
+type WorkoutSplit struct {
+	Furlong int
+	Time    float64
+	Lap     sql.NullFloat64
+}
+
+func (w *Workout) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"horse_id":      w.HorseID,
+		"date":          w.Date,
+		"race_id":       w.RaceID,
+		"course":        w.Course,
+		"course_type":   w.CourseType,
+		"surface_state": util.nullable(w.SurfaceState),
+		"rider":         util.nullable(w.Rider),
+		"intensity":     util.nullable(w.Intensity),
+		"splits":        w.Splits,
+	})
+}
+
+func (s *WorkoutSplit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"furlong": s.Furlong,
+		"time":    s.Time,
+		"lap":     util.nullable(s.Lap),
+	})
+}
+
+// BuildWorkoutRecords builds the workout records from the race workout page.
+func BuildWorkoutRecords(id RaceID, doc *html.Node) ([]*Workout, error) {
+	tr := htmlquery.QuerySelectorAll(doc, xpath.MustCompile(`//table[`+util.xpathContains("@class", "OikiriTable")+`]//tr[`+util.xpathContains("@class", "HorseList")+`]`))
+	if len(tr) == 0 {
+		return nil, xerrors.New(`Missing workout table from HTML, you may need a premium account`)
+	}
+
+	r1 := regexp.MustCompile(`(\d{4})/(\d{2})/(\d{2})`)
+	r2 := regexp.MustCompile(`(\d+\.\d)(?:\((\d+\.\d)\))?`)
+	r3 := regexp.MustCompile(`馬なり|強め|一杯|仕掛|叩き|追って|末強め`)
+
+	var records []*Workout
+
+	for i := 0; i < len(tr); i++ {
+		name := htmlquery.QuerySelector(tr[i], xpath.MustCompile(`//td[.//a[contains(@href, '/horse/')]]`))
+		if name == nil {
+			continue
+		}
+
+		record := &Workout{
+			RaceID:  id,
+			HorseID: HorseID(util.htmlSelectHrefLastSegment(name)),
+		}
+
+		td := htmlquery.QuerySelectorAll(tr[i], xpath.MustCompile(`/td`))
+
+		for j := 0; j < len(td); j++ {
+			m := r1.FindStringSubmatch(util.htmlInnerText(td[j]))
+			if m == nil {
+				continue
+			}
+
+			record.Date = m[1] + "-" + m[2] + "-" + m[3]
+
+			// the date is followed by course, surface state and rider
+			if j+1 < len(td) {
+				record.Course = util.htmlInnerText(td[j+1])
+				record.CourseType = determineWorkoutCourseType(record.Course)
+			}
+			if j+2 < len(td) && util.htmlInnerText(td[j+2]) != "" {
+				record.SurfaceState.Scan(util.htmlInnerText(td[j+2]))
+			}
+			if j+3 < len(td) && util.htmlInnerText(td[j+3]) != "" {
+				record.Rider.Scan(util.htmlInnerText(td[j+3]))
+			}
+
+			break
+		}
+
+		// horses without workout in the period
+		if record.HorseID == "" || record.Date == "" {
+			continue
+		}
+
+		li := htmlquery.QuerySelectorAll(tr[i], xpath.MustCompile(`//li`))
+
+		for j := 0; j < len(li); j++ {
+			m := r2.FindStringSubmatch(util.htmlInnerText(li[j]))
+			if m == nil {
+				continue
+			}
+
+			split := &WorkoutSplit{Furlong: len(li) - j, Time: util.parseFloat(m[1])}
+			if m[2] != "" {
+				split.Lap.Scan(util.parseFloat(m[2]))
+			}
+
+			record.Splits = append(record.Splits, split)
+		}
+
+		for j := 0; j < len(td); j++ {
+			if s := util.htmlInnerText(td[j]); r3.MatchString(s) {
+				record.Intensity.Scan(s)
+				break
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func determineWorkoutCourseType(course string) string {
+	// course looks like "美Ｗ", "栗坂" or "美Ｐ"
+	switch {
+	case strings.Contains(course, "坂"):
+		return WorkoutCourseHill
+	case strings.ContainsAny(course, "WＷ") || strings.Contains(course, "ウッド"):
+		return WorkoutCourseWood
+	case strings.ContainsAny(course, "PＰ") || strings.Contains(course, "ポリ"):
+		return WorkoutCoursePoly
+	case strings.Contains(course, "芝"):
+		return WorkoutCourseTurf
+	case strings.Contains(course, "ダ"):
+		return WorkoutCourseDirt
+	case strings.Contains(course, "プール"):
+		return WorkoutCoursePool
+	default:
+		return WorkoutCourseOther
+	}
+}
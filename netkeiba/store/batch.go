@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Batch is a transaction writing the records of many pages. The records of
+// each page are written in a savepoint by Do, so that a failed page is rolled
+// back alone and the others are still committed.
+type Batch struct {
+	tx         *sql.Tx
+	stmts      map[string]*sql.Stmt
+	importedAt string
+}
+
+// Begin starts a batch. The rows written by the batch are tagged with the
+// parser version and the time the batch started. The batch is rolled back
+// if ctx is canceled before Commit.
+func Begin(ctx context.Context, db *sql.DB) (*Batch, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBatch(tx), nil
+}
+
+func newBatch(tx *sql.Tx) *Batch {
+	return &Batch{
+		tx:         tx,
+		stmts:      make(map[string]*sql.Stmt),
+		importedAt: time.Now().Format(time.RFC3339),
+	}
+}
+
+// Do runs fn in a savepoint. If fn fails, only the records written by fn are
+// rolled back.
+func (b *Batch) Do(ctx context.Context, fn func() error) error {
+	if _, err := b.tx.ExecContext(ctx, `SAVEPOINT page;`); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		b.tx.ExecContext(ctx, `ROLLBACK TO page;`)
+		b.tx.ExecContext(ctx, `RELEASE page;`)
+		return err
+	}
+
+	_, err := b.tx.ExecContext(ctx, `RELEASE page;`)
+
+	return err
+}
+
+// Commit commits the records written by the batch.
+func (b *Batch) Commit() error {
+	b.closeStmts()
+
+	return b.tx.Commit()
+}
+
+// Rollback discards the records written by the batch. It does nothing after
+// Commit.
+func (b *Batch) Rollback() error {
+	b.closeStmts()
+
+	return b.tx.Rollback()
+}
+
+// prepare returns the statement prepared in the transaction, which is reused
+// by the following pages of the batch.
+func (b *Batch) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	if stmt, ok := b.stmts[query]; ok {
+		return stmt, nil
+	}
+
+	stmt, err := b.tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	b.stmts[query] = stmt
+
+	return stmt, nil
+}
+
+// insertQuery returns the statement replacing the row of the table with the
+// values of the columns.
+func insertQuery(table string, columns []string) string {
+	return fmt.Sprintf("INSERT OR REPLACE INTO `%s` (%s) VALUES (?%s);", table, strings.Join(columns, ", "), strings.Repeat(", ?", len(columns)-1))
+}
+
+func (b *Batch) closeStmts() {
+	for query, stmt := range b.stmts {
+		stmt.Close()
+		delete(b.stmts, query)
+	}
+}
+
+// withBatch runs fn in a batch of its own.
+func withBatch(ctx context.Context, db *sql.DB, fn func(b *Batch) error) error {
+	b, err := Begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer b.Rollback()
+
+	if err := fn(b); err != nil {
+		return err
+	}
+
+	return b.Commit()
+}
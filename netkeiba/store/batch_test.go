@@ -0,0 +1,208 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/parse"
+)
+
+// benchmarkPayouts are the payouts of a race, one per ticket type.
+var benchmarkPayouts = []struct {
+	ticketType string
+	betType    string
+	draw       string
+	numbers    []int
+}{
+	{"単勝", parse.BetTypeWin, "3", []int{3}},
+	{"複勝", parse.BetTypePlace, "3", []int{3}},
+	{"枠連", parse.BetTypeBracketQuinella, "1 - 2", []int{1, 2}},
+	{"馬連", parse.BetTypeQuinella, "1 - 3", []int{1, 3}},
+	{"ワイド", parse.BetTypeWide, "1 - 3", []int{1, 3}},
+	{"馬単", parse.BetTypeExacta, "3 → 1", []int{3, 1}},
+	{"三連複", parse.BetTypeTrio, "1 - 3 - 5", []int{1, 3, 5}},
+	{"三連単", parse.BetTypeTrifecta, "3 → 1 → 5", []int{3, 1, 5}},
+}
+
+// benchmarkRacePage returns a race of the usual size, 16 runners and a
+// payout of every ticket type.
+func benchmarkRacePage(id parse.RaceID) *parse.RacePage {
+	page := &parse.RacePage{
+		Race: &parse.Race{
+			ID:             id,
+			Name:           "テストステークス",
+			Course:         "東京",
+			Number:         11,
+			Surface:        "芝",
+			Direction:      "左",
+			Distance:       1600,
+			Weather:        "晴",
+			SurfaceState:   "良",
+			Date:           "2021-05-02",
+			PostTime:       "15:40",
+			Classification: "3歳オープン",
+			Class:          sql.NullString{String: parse.ClassOpen, Valid: true},
+			AgeCondition:   sql.NullString{String: "3歳", Valid: true},
+		},
+	}
+
+	page.Race.ClassificationCode = parse.DetermineClassificationCode(page.Race)
+
+	for i := 0; i < len(benchmarkPayouts); i++ {
+		p := benchmarkPayouts[i]
+
+		page.Payouts = append(page.Payouts, &parse.Payout{
+			RaceID:     id,
+			TicketType: p.ticketType,
+			BetType:    sql.NullString{String: p.betType, Valid: true},
+			Draw:       p.draw,
+			Split:      1,
+			Amount:     float64(250 * (i + 1)),
+			Popularity: 1,
+			Numbers:    p.numbers,
+			Ordered:    p.betType == parse.BetTypeExacta || p.betType == parse.BetTypeTrifecta,
+		})
+	}
+
+	for i := 1; i <= 16; i++ {
+		page.Results = append(page.Results, &parse.Result{
+			RaceID:        id,
+			OrderOfFinish: strconv.Itoa(i),
+			Bracket:       (i + 1) / 2,
+			Draw:          i,
+			HorseID:       parse.HorseID(strconv.Itoa(2018100000 + i)),
+			Horse:         "テストホース",
+			Sex:           "牡",
+			Age:           3,
+			Weight:        57,
+			JockeyID:      "01126",
+			Jockey:        "テスト",
+			Time:          sql.NullString{String: "1:33.5", Valid: true},
+			TimeSec:       sql.NullFloat64{Float64: 93.5, Valid: true},
+			Position:      "2-2",
+			Odds:          2.5,
+			Popularity:    i,
+			HorseWeight:   "480(+2)",
+			Stable:        "東",
+			TrainerID:     "01061",
+			OwnerID:       "x00001",
+			Earnings:      1000,
+		})
+	}
+
+	return page
+}
+
+func setupBenchmarkDatabase(b *testing.B) string {
+	dbFilePath := filepath.Join(b.TempDir(), "race.db")
+
+	if err := Setup(context.Background(), dbFilePath, false); err != nil {
+		b.Fatal(err)
+	}
+
+	return dbFilePath
+}
+
+// TestBenchmarkRacePage checks that every row of the benchmark race is
+// stored, so that the benchmarks write as many rows as a real race.
+func TestBenchmarkRacePage(t *testing.T) {
+	ctx := context.Background()
+
+	dbFilePath := filepath.Join(t.TempDir(), "race.db")
+
+	if err := Setup(ctx, dbFilePath, false); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := Open(dbFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	page := benchmarkRacePage(1)
+
+	if page.Race.ClassificationCode != "TM3" {
+		t.Errorf("classification code = %q, want TM3", page.Race.ClassificationCode)
+	}
+
+	if err := InsertRacePage(ctx, db, page); err != nil {
+		t.Fatal(err)
+	}
+
+	for table, want := range map[string]int{"payout": len(benchmarkPayouts), "result": 16} {
+		var n int
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != want {
+			t.Errorf("%d rows in %s, want %d", n, table, want)
+		}
+	}
+}
+
+// BenchmarkInsertRacePage is the import before the pipeline, a transaction
+// per race with the rollback journal.
+func BenchmarkInsertRacePage(b *testing.B) {
+	ctx := context.Background()
+
+	db, err := sql.Open("sqlite3", "file:"+setupBenchmarkDatabase(b)+"?_journal_mode=DELETE")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := InsertRacePage(ctx, db, benchmarkRacePage(parse.RaceID(i+1))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkBatchInsertRacePage is the writer of the pipeline, 500 races per
+// transaction in WAL mode.
+func BenchmarkBatchInsertRacePage(b *testing.B) {
+	ctx := context.Background()
+
+	db, err := Open(setupBenchmarkDatabase(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	b.ResetTimer()
+
+	var batch *Batch
+
+	for i := 0; i < b.N; i++ {
+		if batch == nil {
+			if batch, err = Begin(ctx, db); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		page := benchmarkRacePage(parse.RaceID(i + 1))
+
+		if err := batch.Do(ctx, func() error { return batch.InsertRacePage(ctx, page) }); err != nil {
+			b.Fatal(err)
+		}
+
+		if (i+1)%500 == 0 {
+			if err := batch.Commit(); err != nil {
+				b.Fatal(err)
+			}
+			batch = nil
+		}
+	}
+
+	if batch != nil {
+		if err := batch.Commit(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/parse"
+)
+
+// InsertHorses upserts the pedigree records. The fifth generation has no
+// parents on the page, so the parents imported from their own pedigree page
+// are kept.
+func InsertHorses(ctx context.Context, db *sql.DB, records []*parse.Horse) error {
+	return withBatch(ctx, db, func(b *Batch) error {
+		return b.InsertHorses(ctx, records)
+	})
+}
+
+// InsertHorses upserts the pedigree records in the batch.
+func (b *Batch) InsertHorses(ctx context.Context, records []*parse.Horse) error {
+	n := len(records)
+	if n == 0 {
+		return nil
+	}
+
+	values, args := make([]string, n), make([]interface{}, n*6)
+
+	pos := 0
+	for i := 0; i < n; i++ {
+		values[i] = "(?, ?, ?, ?, ?, ?)"
+		args[pos] = records[i].ID
+		args[pos+1] = records[i].Name
+		args[pos+2] = records[i].SireID
+		args[pos+3] = records[i].DamID
+		args[pos+4] = parse.Version
+		args[pos+5] = b.importedAt
+		pos += 6
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO horse (id, name, sire_id, dam_id, parser_version, imported_at) VALUES %s ON CONFLICT (id) DO UPDATE SET name = excluded.name, sire_id = COALESCE(excluded.sire_id, horse.sire_id), dam_id = COALESCE(excluded.dam_id, horse.dam_id), parser_version = excluded.parser_version, imported_at = excluded.imported_at",
+		strings.Join(values, ", "),
+	)
+
+	_, err := b.tx.ExecContext(ctx, query, args...)
+
+	return err
+}
+
+// InsertHorseProfile upserts the breeding data of the horse. The row may
+// already exist as an ancestor imported from a pedigree page, so its sire
+// and dam are kept untouched.
+func InsertHorseProfile(ctx context.Context, db *sql.DB, record *parse.HorseProfile) error {
+	return withBatch(ctx, db, func(b *Batch) error {
+		return b.InsertHorseProfile(ctx, record)
+	})
+}
+
+// InsertHorseProfile upserts the breeding data of the horse in the batch.
+func (b *Batch) InsertHorseProfile(ctx context.Context, record *parse.HorseProfile) error {
+	_, err := b.tx.ExecContext(
+		ctx,
+		`INSERT INTO horse (id, name, breeder_id, breeder, birthplace, region, parser_version, imported_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET breeder_id = excluded.breeder_id, breeder = excluded.breeder, birthplace = excluded.birthplace, region = excluded.region, parser_version = excluded.parser_version, imported_at = excluded.imported_at;`,
+		record.ID,
+		record.Name,
+		record.BreederID,
+		record.Breeder,
+		record.Birthplace,
+		record.Region,
+		parse.Version,
+		b.importedAt,
+	)
+
+	return err
+}
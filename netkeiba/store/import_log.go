@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ImportLog is a file imported into the database. The file is imported again
+// when its content or the parser version has changed.
+type ImportLog struct {
+	Path          string
+	Size          int64
+	ModTime       time.Time
+	Hash          string
+	ParserVersion int
+	ImportedAt    time.Time
+}
+
+// SelectImportLogs returns the import logs by path.
+func SelectImportLogs(ctx context.Context, db *sql.DB) (map[string]*ImportLog, error) {
+	rows, err := db.QueryContext(ctx, `SELECT path, size, mtime, hash, parser_version, imported_at FROM import_log;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := make(map[string]*ImportLog)
+
+	for rows.Next() {
+		var l ImportLog
+		var mtime, importedAt string
+
+		if err := rows.Scan(&l.Path, &l.Size, &mtime, &l.Hash, &l.ParserVersion, &importedAt); err != nil {
+			return nil, err
+		}
+
+		l.ModTime, _ = time.Parse(time.RFC3339Nano, mtime)
+		l.ImportedAt, _ = time.Parse(time.RFC3339Nano, importedAt)
+
+		logs[l.Path] = &l
+	}
+
+	return logs, rows.Err()
+}
+
+// InsertImportLog replaces the import log of the file in the batch.
+func (b *Batch) InsertImportLog(ctx context.Context, l *ImportLog) error {
+	stmt, err := b.prepare(ctx, `INSERT OR REPLACE INTO import_log VALUES (?, ?, ?, ?, ?, ?);`)
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(
+		ctx,
+		l.Path,
+		l.Size,
+		l.ModTime.Format(time.RFC3339Nano),
+		l.Hash,
+		l.ParserVersion,
+		l.ImportedAt.Format(time.RFC3339Nano),
+	)
+
+	return err
+}
@@ -0,0 +1,495 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/parse"
+	"golang.org/x/xerrors"
+)
+
+// migrations upgrade the databases created by older versions. The number of
+// the migrations applied is kept in PRAGMA user_version, and the database
+// created from the schema has all of them. The tables missing from the
+// database are created from the schema afterwards, so a migration only
+// changes the tables which exist. The columns added by a migration are at the
+// end of the table, so the rows are inserted by column names.
+var migrations = []func(ctx context.Context, tx *sql.Tx) error{
+	// the parser version and the import timestamp of every row
+	func(ctx context.Context, tx *sql.Tx) error {
+		for _, table := range taggedTables {
+			if err := addColumn(ctx, tx, table, "parser_version", "INTEGER"); err != nil {
+				return err
+			}
+			if err := addColumn(ctx, tx, table, "imported_at", "TEXT"); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	// the finish status decoded from the order of finish, and the field size
+	func(ctx context.Context, tx *sql.Tx) error {
+		for _, c := range []struct{ table, column, definition string }{
+			{"result", "finish_position", "INTEGER"},
+			{"result", "finish_status", "TEXT"},
+			{"result", "demoted", "INTEGER NOT NULL DEFAULT 0"},
+			{"result", "original_placing", "INTEGER"},
+			{"race", "field_size", "INTEGER"},
+		} {
+			if err := addColumn(ctx, tx, c.table, c.column, c.definition); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	// the body weight decoded from the horse weight
+	func(ctx context.Context, tx *sql.Tx) error {
+		for _, column := range []string{"body_weight", "body_weight_delta"} {
+			if err := addColumn(ctx, tx, "result", column, "INTEGER"); err != nil {
+				return err
+			}
+		}
+		if err := addColumn(ctx, tx, "result", "body_weight_status", "TEXT"); err != nil {
+			return err
+		}
+		return backfillBodyWeight(ctx, tx)
+	},
+	// the running style, with the positions at the corners in result_position
+	func(ctx context.Context, tx *sql.Tx) error {
+		return addColumn(ctx, tx, "result", "running_style", "TEXT")
+	},
+	// the winning margin in lengths
+	func(ctx context.Context, tx *sql.Tx) error {
+		for _, column := range []string{"margin_lengths", "lengths_behind"} {
+			if err := addColumn(ctx, tx, "result", column, "REAL"); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	// the bet type of the payout, with the numbers of the draw in
+	// payout_combination
+	func(ctx context.Context, tx *sql.Tx) error {
+		if err := addColumn(ctx, tx, "payout", "bet_type", "TEXT"); err != nil {
+			return err
+		}
+		return addColumn(ctx, tx, "payout", "ordered", "INTEGER NOT NULL DEFAULT 0")
+	},
+	// the dead heats, with the split dividends of the same draw in the keys
+	// of the payouts
+	func(ctx context.Context, tx *sql.Tx) error {
+		if err := addColumn(ctx, tx, "result", "dead_heat", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+		if err := rebuildTable(ctx, tx, "payout", `
+    race_id        INTEGER NOT NULL,
+    ticket_type    TEXT    NOT NULL,
+    draw           TEXT    NOT NULL,
+    amount         REAL    NOT NULL,
+    popularity     INTEGER NOT NULL,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    bet_type       TEXT,
+    ordered        INTEGER NOT NULL DEFAULT 0,
+    split          INTEGER NOT NULL DEFAULT 1,
+    PRIMARY KEY (race_id, ticket_type, draw, split),
+    FOREIGN KEY (race_id) REFERENCES race(id)`); err != nil {
+			return err
+		}
+		return rebuildTable(ctx, tx, "payout_combination", `
+    race_id        INTEGER NOT NULL,
+    ticket_type    TEXT    NOT NULL,
+    draw           TEXT    NOT NULL,
+    split          INTEGER NOT NULL DEFAULT 1,
+    position       INTEGER NOT NULL,
+    number         INTEGER NOT NULL,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (race_id, ticket_type, draw, split, position),
+    FOREIGN KEY (race_id, ticket_type, draw, split) REFERENCES payout(race_id, ticket_type, draw, split)`)
+	},
+	// the alphanumeric horse IDs, which were stored as 0
+	func(ctx context.Context, tx *sql.Tx) error {
+		if err := rebuildTable(ctx, tx, "result", `
+    race_id            INTEGER  NOT NULL,
+    order_of_finish    TEXT     NOT NULL,
+    finish_position    INTEGER,
+    finish_status      TEXT,
+    demoted            INTEGER  NOT NULL DEFAULT 0,
+    original_placing   INTEGER,
+    dead_heat          INTEGER  NOT NULL DEFAULT 0,
+    bracket            INTEGER  NOT NULL,
+    draw               INTEGER  NOT NULL,
+    horse_id           TEXT     NOT NULL,
+    horse              TEXT     NOT NULL,
+    sex                TEXT     NOT NULL,
+    age                INTEGER  NOT NULL,
+    weight             REAL     NOT NULL,
+    jockey_id          TEXT     NOT NULL,
+    jockey             TEXT     NOT NULL,
+    time               TEXT,
+    time_sec           INTEGER,
+    winning_margin     TEXT     NOT NULL,
+    margin_lengths     REAL,
+    lengths_behind     REAL,
+    speed_index        INTEGER,
+    position           TEXT     NOT NULL,
+    running_style      TEXT,
+    sectional_time     REAL,
+    odds               REAL,
+    popularity         INTEGER,
+    horse_weight       TEXT     NOT NULL,
+    body_weight        INTEGER,
+    body_weight_delta  INTEGER,
+    body_weight_status TEXT,
+    note               TEXT,
+    stable             TEXT     NOT NULL,
+    trainer_id         TEXT     NOT NULL,
+    owner_id           TEXT     NOT NULL,
+    earnings           REAL,
+    parser_version     INTEGER,
+    imported_at        TEXT,
+    PRIMARY KEY (race_id, horse_id),
+    FOREIGN KEY (race_id) REFERENCES race(id),
+    FOREIGN KEY (horse_id) REFERENCES horse(id),
+    FOREIGN KEY (jockey_id) REFERENCES jockey(id),
+    FOREIGN KEY (trainer_id) REFERENCES trainer(id),
+    FOREIGN KEY (owner_id) REFERENCES owner(id)`); err != nil {
+			return err
+		}
+		if err := rebuildTable(ctx, tx, "result_position", `
+    race_id        INTEGER NOT NULL,
+    horse_id       TEXT    NOT NULL,
+    corner         INTEGER NOT NULL,
+    position       INTEGER NOT NULL,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (race_id, horse_id, corner),
+    FOREIGN KEY (race_id, horse_id) REFERENCES result(race_id, horse_id)`); err != nil {
+			return err
+		}
+		return nil
+	},
+	// the class and the ages normalized across the eras
+	func(ctx context.Context, tx *sql.Tx) error {
+		for _, c := range []struct{ table, column, definition string }{
+			{"race", "class", "TEXT"},
+			{"race", "age_condition", "TEXT"},
+			{"result", "normalized_age", "INTEGER"},
+		} {
+			if err := addColumn(ctx, tx, c.table, c.column, c.definition); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	// the direction, the layout and the obstacle surface of the course
+	func(ctx context.Context, tx *sql.Tx) error {
+		for _, c := range []struct{ table, column, definition string }{
+			{"race", "track_direction", "TEXT"},
+			{"race", "track_layout", "TEXT"},
+			{"race", "laps", "INTEGER"},
+			{"race", "obstacle_surface", "TEXT"},
+		} {
+			if err := addColumn(ctx, tx, c.table, c.column, c.definition); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	// the breeder and the birthplace of the horse, which were added to the
+	// schema before the migrations
+	func(ctx context.Context, tx *sql.Tx) error {
+		for _, column := range []string{"breeder_id", "breeder", "birthplace", "region"} {
+			if err := addColumn(ctx, tx, "horse", column, "TEXT"); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	// the results of the horses stored as 0 before the alphanumeric horse
+	// IDs, parsed again from the race result pages
+	func(ctx context.Context, tx *sql.Tx) error {
+		return repairBrokenHorseIDs(ctx, tx)
+	},
+}
+
+// taggedTables are the tables whose rows are tagged with the parser version
+// and the import timestamp.
+var taggedTables = []string{
+	"race",
+	"race_classification",
+	"result",
+	"result_position",
+	"payout",
+	"payout_combination",
+	"horse",
+	"jockey",
+	"jockey_stats",
+	"trainer",
+	"trainer_stats",
+	"owner",
+	"owner_stats",
+	"breeder",
+	"breeder_stats",
+	"workout",
+	"workout_split",
+}
+
+func migrate(ctx context.Context, db *sql.DB) error {
+	var version int
+
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version;`).Scan(&version); err != nil {
+		return err
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		if err := migrations[version](ctx, tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d;`, version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillBodyWeight decodes the horse weight of the result rows. The rows
+// whose horse weight is not understood are left NULL.
+func backfillBodyWeight(ctx context.Context, tx *sql.Tx) error {
+	if names, err := columns(ctx, tx, "result"); err != nil || len(names) == 0 {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT rowid, horse_weight FROM result;`)
+	if err != nil {
+		return err
+	}
+
+	weights := make(map[int64]*parse.BodyWeight)
+
+	for rows.Next() {
+		var rowid int64
+		var s string
+
+		if err := rows.Scan(&rowid, &s); err != nil {
+			rows.Close()
+			return err
+		}
+
+		if w, err := parse.ParseBodyWeight(s); err == nil {
+			weights[rowid] = w
+		}
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `UPDATE result SET body_weight = ?, body_weight_delta = ?, body_weight_status = ? WHERE rowid = ?;`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for rowid, w := range weights {
+		if _, err := stmt.ExecContext(ctx, w.Weight, w.Delta, w.Status, rowid); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// repairBrokenHorseIDs replaces the results of the races which have a horse
+// stored as 0 by the older versions with the results parsed again from the
+// race result page in RacePageDir. The results of the races whose page is
+// missing are deleted with the import logs of the races, so that they are
+// imported when the page is dumped again. The tables it writes are created
+// first, as they may be older than the database.
+func repairBrokenHorseIDs(ctx context.Context, tx *sql.Tx) error {
+	if names, err := columns(ctx, tx, "result"); err != nil || len(names) == 0 {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT DISTINCT race_id FROM result WHERE horse_id = '0';`)
+	if err != nil {
+		return err
+	}
+
+	var ids []parse.RaceID
+
+	for rows.Next() {
+		var id parse.RaceID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil || len(ids) == 0 {
+		return err
+	}
+
+	for _, table := range []string{"result_position", "import_log"} {
+		if err := createTable(ctx, tx, table); err != nil {
+			return err
+		}
+	}
+
+	b := newBatch(tx)
+	defer b.closeStmts()
+
+	for i := 0; i < len(ids); i++ {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM result WHERE race_id = ?;`, ids[i]); err != nil {
+			return err
+		}
+
+		page, err := readRacePage(ids[i])
+		if err == nil {
+			if err := b.insertResults(ctx, ids[i], page.Results); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, query := range []string{
+			`DELETE FROM result_position WHERE race_id = ?;`,
+			`DELETE FROM import_log WHERE path = ? || '.html';`,
+		} {
+			if _, err := tx.ExecContext(ctx, query, ids[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// readRacePage parses the race result page of the race in RacePageDir.
+func readRacePage(id parse.RaceID) (*parse.RacePage, error) {
+	if RacePageDir == "" {
+		return nil, xerrors.New("no race page directory")
+	}
+
+	file, err := os.Open(filepath.Join(RacePageDir, id.String()+".html"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parse.ReadRacePage(id, file)
+}
+
+// columns returns the columns of the table, or nothing if the table does not
+// exist.
+func columns(ctx context.Context, q interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}, table string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT name FROM pragma_table_info('%s');", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+
+	for rows.Next() {
+		var name string
+
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// rebuildTable recreates the table with the definition, which SQLite needs to
+// change the primary key, and copies the rows unless the table does not exist.
+func rebuildTable(ctx context.Context, tx *sql.Tx, table string, definition string) error {
+	names, err := columns(ctx, tx, table)
+	if err != nil || len(names) == 0 {
+		return err
+	}
+
+	for i := 0; i < len(names); i++ {
+		names[i] = "`" + names[i] + "`"
+	}
+
+	list := strings.Join(names, ", ")
+
+	for _, query := range []string{
+		fmt.Sprintf("CREATE TABLE `%s_new` (%s\n);", table, definition),
+		fmt.Sprintf("INSERT INTO `%s_new` (%s) SELECT %s FROM `%s`;", table, list, list, table),
+		fmt.Sprintf("DROP TABLE `%s`;", table),
+		fmt.Sprintf("ALTER TABLE `%s_new` RENAME TO `%s`;", table, table),
+	} {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createTable creates the table from its statement in the schema unless it
+// exists.
+func createTable(ctx context.Context, tx *sql.Tx, table string) error {
+	start := strings.Index(schema, "CREATE TABLE IF NOT EXISTS `"+table+"`")
+	if start < 0 {
+		return xerrors.Errorf("table %s is not in the schema", table)
+	}
+
+	end := strings.Index(schema[start:], ";")
+	if end < 0 {
+		return xerrors.Errorf("table %s is not terminated in the schema", table)
+	}
+
+	_, err := tx.ExecContext(ctx, schema[start:start+end+1])
+
+	return err
+}
+
+// addColumn adds the column to the table unless the table does not exist or
+// already has it.
+func addColumn(ctx context.Context, tx *sql.Tx, table string, column string, definition string) error {
+	names, err := columns(ctx, tx, table)
+	if err != nil || len(names) == 0 {
+		return err
+	}
+
+	for i := 0; i < len(names); i++ {
+		if names[i] == column {
+			return nil
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN %s %s;", table, column, definition))
+
+	return err
+}
@@ -0,0 +1,360 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// schemaObjects returns the columns of the tables and the indexes of the
+// database, sorted by name, so that the databases upgraded by the
+// migrations can be compared with the one created from the schema.
+func schemaObjects(t *testing.T, db *sql.DB) map[string][]string {
+	t.Helper()
+
+	ctx := context.Background()
+
+	rows, err := db.QueryContext(ctx, `SELECT type, name, tbl_name FROM sqlite_master WHERE type IN ('table', 'index') AND name NOT LIKE 'sqlite_%';`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	objects := map[string][]string{}
+	var tables []string
+
+	for rows.Next() {
+		var kind, name, table string
+		if err := rows.Scan(&kind, &name, &table); err != nil {
+			t.Fatal(err)
+		}
+
+		if kind == "index" {
+			objects["index "+table] = append(objects["index "+table], name)
+			continue
+		}
+		tables = append(tables, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < len(tables); i++ {
+		names, err := columns(ctx, db, tables[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		objects["table "+tables[i]] = names
+	}
+
+	for _, names := range objects {
+		sort.Strings(names)
+	}
+
+	return objects
+}
+
+func userVersion(t *testing.T, db *sql.DB) int {
+	t.Helper()
+
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version;`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+
+	return version
+}
+
+// schemaFilePattern matches the files in testdata/schema, which are named
+// after the user_version of the schema, e.g. v3.sql, and the tables added to
+// the schema without a migration, e.g. v0_workouts.sql.
+var schemaFilePattern = regexp.MustCompile(`^v(\d+)(?:_\w+)?\.sql$`)
+
+// createDatabase creates the database from the schema of an older version
+// in testdata/schema, as that version did.
+func createDatabase(t *testing.T, schemaFile string) string {
+	t.Helper()
+
+	m := schemaFilePattern.FindStringSubmatch(schemaFile)
+	if m == nil {
+		t.Fatalf("unexpected schema file %s", schemaFile)
+	}
+	version, _ := strconv.Atoi(m[1])
+
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "schema", schemaFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dbFilePath := filepath.Join(t.TempDir(), "race.db")
+
+	db, err := Open(dbFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(string(b)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec(`PRAGMA user_version = ` + strconv.Itoa(version) + `;`); err != nil {
+		t.Fatal(err)
+	}
+
+	return dbFilePath
+}
+
+func TestSetupUpgrade(t *testing.T) {
+	ctx := context.Background()
+
+	latest := filepath.Join(t.TempDir(), "race.db")
+	if err := Setup(ctx, latest, false); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := Open(latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := schemaObjects(t, db)
+	db.Close()
+
+	files, err := filepath.Glob(filepath.Join("testdata", "schema", "v*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no schema in testdata/schema")
+	}
+
+	for i := 0; i < len(files); i++ {
+		schemaFile := filepath.Base(files[i])
+
+		t.Run(schemaFile, func(t *testing.T) {
+			dbFilePath := createDatabase(t, schemaFile)
+
+			if err := Setup(ctx, dbFilePath, false); err != nil {
+				t.Fatal(err)
+			}
+
+			// the migrations are idempotent, and the upgraded database
+			// is set up again on every command
+			if err := Setup(ctx, dbFilePath, false); err != nil {
+				t.Fatal(err)
+			}
+
+			db, err := Open(dbFilePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			if version := userVersion(t, db); version != len(migrations) {
+				t.Errorf("user_version = %d, want %d", version, len(migrations))
+			}
+
+			got := schemaObjects(t, db)
+
+			for name, names := range want {
+				if !reflect.DeepEqual(got[name], names) {
+					t.Errorf("%s = %v, want %v", name, got[name], names)
+				}
+			}
+			for name := range got {
+				if _, ok := want[name]; !ok {
+					t.Errorf("unexpected %s", name)
+				}
+			}
+
+			if err := InsertRacePage(ctx, db, benchmarkRacePage(202105021211)); err != nil {
+				t.Errorf("InsertRacePage() = %v", err)
+			}
+		})
+	}
+}
+
+func TestSetupUpgradeKeepsRows(t *testing.T) {
+	ctx := context.Background()
+
+	dbFilePath := createDatabase(t, "v0.sql")
+
+	db, err := Open(dbFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{
+		`INSERT INTO race VALUES (202105021211, 'テストステークス', '東京', 11, '芝', '左', 1600, '晴', '良', NULL, '2021-05-02', '15:40', '3歳オープン', 'TM3');`,
+		`INSERT INTO result (race_id, order_of_finish, bracket, draw, horse_id, horse, sex, age, weight, jockey_id, jockey, winning_margin, position, horse_weight, stable, trainer_id, owner_id) VALUES (202105021211, '1', 1, 1, 2018105027, 'テストホース', '牡', 3, 57, '01126', 'テスト', '', '2-2', '480(+2)', '東', '01061', 'x00001');`,
+		`INSERT INTO payout VALUES (202105021211, '単勝', '1', 250, 1);`,
+		`INSERT INTO horse VALUES ('2018105027', 'テストホース', NULL, NULL);`,
+	} {
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	if err := Setup(ctx, dbFilePath, false); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = Open(dbFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var horseID string
+	var bodyWeight, bodyWeightDelta int
+	if err := db.QueryRowContext(ctx, `SELECT horse_id, body_weight, body_weight_delta FROM result WHERE race_id = 202105021211;`).Scan(&horseID, &bodyWeight, &bodyWeightDelta); err != nil {
+		t.Fatal(err)
+	}
+	if horseID != "2018105027" || bodyWeight != 480 || bodyWeightDelta != 2 {
+		t.Errorf("result = %q %d %d, want %q %d %d", horseID, bodyWeight, bodyWeightDelta, "2018105027", 480, 2)
+	}
+
+	var split int
+	if err := db.QueryRowContext(ctx, `SELECT split FROM payout WHERE race_id = 202105021211;`).Scan(&split); err != nil {
+		t.Fatal(err)
+	}
+	if split != 1 {
+		t.Errorf("payout.split = %d, want 1", split)
+	}
+
+	var name string
+	if err := db.QueryRowContext(ctx, `SELECT name FROM horse WHERE id = '2018105027' AND breeder_id IS NULL;`).Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetupRepairsHorseIDs(t *testing.T) {
+	ctx := context.Background()
+
+	page, err := ioutil.ReadFile(filepath.Join("..", "parse", "testdata", "race", "202105021211_flat_turf.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the horses bred abroad were stored as 0 until user_version 8
+	tests := []struct {
+		name      string
+		schema    string
+		page      bool
+		results   int
+		importLog int
+	}{
+		{name: "page", schema: "v7.sql", page: true, results: 6, importLog: 1},
+		{name: "missing page", schema: "v7.sql", page: false, results: 0, importLog: 0},
+		{name: "before result_position", schema: "v3.sql", page: true, results: 6, importLog: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RacePageDir = t.TempDir()
+			defer func() { RacePageDir = "" }()
+
+			if tt.page {
+				if err := ioutil.WriteFile(filepath.Join(RacePageDir, "202105021211.html"), page, 0666); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			dbFilePath := createDatabase(t, tt.schema)
+
+			db, err := Open(dbFilePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, query := range []string{
+				`INSERT INTO result (race_id, order_of_finish, bracket, draw, horse_id, horse, sex, age, weight, jockey_id, jockey, winning_margin, position, horse_weight, stable, trainer_id, owner_id) VALUES (202105021211, '1', 1, 1, 0, 'テストホース', '牡', 3, 57, '01126', 'テスト', '', '2-2', '480(+2)', '東', '01061', 'x00001');`,
+				`INSERT INTO import_log VALUES ('202105021211.html', 1, '2021-05-03T00:00:00Z', '', 8, '2021-05-03T00:00:00Z');`,
+			} {
+				if _, err := db.ExecContext(ctx, query); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if names, _ := columns(ctx, db, "result_position"); 0 < len(names) {
+				if _, err := db.ExecContext(ctx, `INSERT INTO result_position VALUES (202105021211, 0, 1, 2, 8, '2021-05-03T00:00:00Z');`); err != nil {
+					t.Fatal(err)
+				}
+			}
+			db.Close()
+
+			if err := Setup(ctx, dbFilePath, false); err != nil {
+				t.Fatal(err)
+			}
+
+			db, err = Open(dbFilePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			for _, c := range []struct {
+				query string
+				want  int
+			}{
+				{`SELECT COUNT(*) FROM result WHERE race_id = 202105021211;`, tt.results},
+				{`SELECT COUNT(*) FROM result WHERE horse_id = '0';`, 0},
+				{`SELECT COUNT(*) FROM result_position WHERE horse_id = '0';`, 0},
+				{`SELECT COUNT(*) FROM import_log;`, tt.importLog},
+			} {
+				var n int
+				if err := db.QueryRowContext(ctx, c.query).Scan(&n); err != nil {
+					t.Fatal(err)
+				}
+				if n != c.want {
+					t.Errorf("%s = %d, want %d", c.query, n, c.want)
+				}
+			}
+
+			if tt.page {
+				var n int
+				if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM result_position WHERE horse_id = '000a013d2f';`).Scan(&n); err != nil {
+					t.Fatal(err)
+				}
+				if n == 0 {
+					t.Errorf("positions of 000a013d2f are missing")
+				}
+			}
+
+			// the repair is a migration, which does not run again
+			if _, err := db.ExecContext(ctx, `UPDATE result SET horse_id = '0' WHERE race_id = 202105021211 AND draw = 1;`); err != nil {
+				t.Fatal(err)
+			}
+			db.Close()
+
+			if err := Setup(ctx, dbFilePath, false); err != nil {
+				t.Fatal(err)
+			}
+
+			db, err = Open(dbFilePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := 0
+			if tt.page {
+				want = 1
+			}
+
+			var n int
+			if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM result WHERE horse_id = '0';`).Scan(&n); err != nil {
+				t.Fatal(err)
+			}
+			if n != want {
+				t.Errorf("%d results of horse 0 after the second setup, want %d", n, want)
+			}
+			db.Close()
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/parse"
+	"golang.org/x/xerrors"
+)
+
+// InsertProfile replaces the jockey, trainer or owner record and its yearly
+// stats. kind is one of parse.ProfileKindJockey, parse.ProfileKindTrainer
+// and parse.ProfileKindOwner.
+func InsertProfile(ctx context.Context, db *sql.DB, kind string, profile *parse.Profile, stats []*parse.ProfileStats) error {
+	return withBatch(ctx, db, func(b *Batch) error {
+		return b.InsertProfile(ctx, kind, profile, stats)
+	})
+}
+
+// InsertProfile replaces the jockey, trainer or owner record and its yearly
+// stats in the batch.
+func (b *Batch) InsertProfile(ctx context.Context, kind string, profile *parse.Profile, stats []*parse.ProfileStats) error {
+	return b.insertProfile(ctx, kind, []interface{}{
+		profile.ID,
+		profile.Name,
+		profile.Kana,
+		profile.Affiliation,
+		profile.LicenseYear,
+		profile.BirthDate,
+	}, stats)
+}
+
+// InsertBreeder replaces the breeder record and its yearly stats.
+func InsertBreeder(ctx context.Context, db *sql.DB, breeder *parse.Breeder, stats []*parse.ProfileStats) error {
+	return withBatch(ctx, db, func(b *Batch) error {
+		return b.InsertBreeder(ctx, breeder, stats)
+	})
+}
+
+// InsertBreeder replaces the breeder record and its yearly stats in the
+// batch.
+func (b *Batch) InsertBreeder(ctx context.Context, breeder *parse.Breeder, stats []*parse.ProfileStats) error {
+	return b.insertProfile(ctx, parse.ProfileKindBreeder, []interface{}{
+		breeder.ID,
+		breeder.Name,
+		breeder.Location,
+		breeder.Region,
+	}, stats)
+}
+
+func (b *Batch) insertProfile(ctx context.Context, kind string, values []interface{}, stats []*parse.ProfileStats) error {
+	switch kind {
+	case parse.ProfileKindJockey, parse.ProfileKindTrainer, parse.ProfileKindOwner, parse.ProfileKindBreeder:
+	default:
+		return xerrors.Errorf("unknown profile kind: %s", kind)
+	}
+
+	values = append(values, parse.Version, b.importedAt)
+
+	s1, err := b.prepare(ctx, fmt.Sprintf(`INSERT OR REPLACE INTO %s VALUES (%s);`, kind, placeholders(len(values))))
+	if err != nil {
+		return err
+	}
+
+	if _, err := s1.ExecContext(ctx, values...); err != nil {
+		return err
+	}
+
+	s2, err := b.prepare(ctx, fmt.Sprintf(`INSERT OR REPLACE INTO %s_stats VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`, kind))
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(stats); i++ {
+		if _, err := s2.ExecContext(
+			ctx,
+			stats[i].ID,
+			stats[i].Year,
+			stats[i].Rank,
+			stats[i].First,
+			stats[i].Second,
+			stats[i].Third,
+			stats[i].Unplaced,
+			stats[i].WinRate,
+			stats[i].Earnings,
+			parse.Version,
+			b.importedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func placeholders(n int) string {
+	if n < 1 {
+		return ""
+	}
+	return strings.Repeat("?, ", n-1) + "?"
+}
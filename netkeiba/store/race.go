@@ -0,0 +1,415 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/riverside-jp/go-netkeiba-scraper/netkeiba/parse"
+)
+
+// InsertRacePage replaces the race, payout and result records of the race,
+// the numbers of the payout draws, the positions of the horses at the corners
+// and the classifications of the race.
+func InsertRacePage(ctx context.Context, db *sql.DB, page *parse.RacePage) error {
+	return withBatch(ctx, db, func(b *Batch) error {
+		return b.InsertRacePage(ctx, page)
+	})
+}
+
+// InsertRacePage replaces the race, payout and result records of the race,
+// the numbers of the payout draws, the positions of the horses at the corners
+// and the classifications of the race, in the batch.
+func (b *Batch) InsertRacePage(ctx context.Context, page *parse.RacePage) error {
+	s1, err := b.prepare(ctx, insertQuery("race", []string{
+		"id",
+		"name",
+		"course",
+		"number",
+		"surface",
+		"direction",
+		"track_direction",
+		"track_layout",
+		"laps",
+		"obstacle_surface",
+		"distance",
+		"weather",
+		"surface_state",
+		"surface_index",
+		"date",
+		"post_time",
+		"classification",
+		"classification_code",
+		"class",
+		"age_condition",
+		"field_size",
+		"parser_version",
+		"imported_at",
+	}))
+	if err != nil {
+		return err
+	}
+
+	race := page.Race
+
+	if _, err := s1.ExecContext(
+		ctx,
+		race.ID,
+		race.Name,
+		race.Course,
+		race.Number,
+		race.Surface,
+		race.Direction,
+		race.TrackDirection,
+		race.TrackLayout,
+		race.Laps,
+		race.ObstacleSurface,
+		race.Distance,
+		race.Weather,
+		race.SurfaceState,
+		race.SurfaceIndex,
+		race.Date,
+		race.PostTime,
+		race.Classification,
+		race.ClassificationCode,
+		race.Class,
+		race.AgeCondition,
+		race.FieldSize,
+		parse.Version,
+		b.importedAt,
+	); err != nil {
+		return err
+	}
+
+	s2, err := b.prepare(ctx, insertQuery("payout", []string{
+		"race_id",
+		"ticket_type",
+		"bet_type",
+		"draw",
+		"split",
+		"amount",
+		"popularity",
+		"ordered",
+		"parser_version",
+		"imported_at",
+	}))
+	if err != nil {
+		return err
+	}
+
+	payouts := page.Payouts
+
+	for i := 0; i < len(payouts); i++ {
+		if _, err := s2.ExecContext(
+			ctx,
+			payouts[i].RaceID,
+			payouts[i].TicketType,
+			payouts[i].BetType,
+			payouts[i].Draw,
+			payouts[i].Split,
+			payouts[i].Amount,
+			payouts[i].Popularity,
+			payouts[i].Ordered,
+			parse.Version,
+			b.importedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	s3, err := b.prepare(ctx, `DELETE FROM payout_combination WHERE race_id = ?;`)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s3.ExecContext(ctx, page.Race.ID); err != nil {
+		return err
+	}
+
+	s4, err := b.prepare(ctx, `INSERT INTO payout_combination VALUES (?, ?, ?, ?, ?, ?, ?, ?);`)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(payouts); i++ {
+		for j := 0; j < len(payouts[i].Numbers); j++ {
+			if _, err := s4.ExecContext(
+				ctx,
+				payouts[i].RaceID,
+				payouts[i].TicketType,
+				payouts[i].Draw,
+				payouts[i].Split,
+				j+1,
+				payouts[i].Numbers[j],
+				parse.Version,
+				b.importedAt,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := b.insertResults(ctx, race.ID, page.Results); err != nil {
+		return err
+	}
+
+	s5, err := b.prepare(ctx, `DELETE FROM race_classification WHERE race_id = ?;`)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s5.ExecContext(ctx, page.Race.ID); err != nil {
+		return err
+	}
+
+	return b.insertRaceClassifications(ctx, page.Classifications)
+}
+
+// insertResults replaces the result records of the race and the positions of
+// the horses at the corners.
+func (b *Batch) insertResults(ctx context.Context, id parse.RaceID, results []*parse.Result) error {
+	s1, err := b.prepare(ctx, insertQuery("result", []string{
+		"race_id",
+		"order_of_finish",
+		"finish_position",
+		"finish_status",
+		"demoted",
+		"original_placing",
+		"dead_heat",
+		"bracket",
+		"draw",
+		"horse_id",
+		"horse",
+		"sex",
+		"age",
+		"normalized_age",
+		"weight",
+		"jockey_id",
+		"jockey",
+		"time",
+		"time_sec",
+		"winning_margin",
+		"margin_lengths",
+		"lengths_behind",
+		"speed_index",
+		"position",
+		"running_style",
+		"sectional_time",
+		"odds",
+		"popularity",
+		"horse_weight",
+		"body_weight",
+		"body_weight_delta",
+		"body_weight_status",
+		"note",
+		"stable",
+		"trainer_id",
+		"owner_id",
+		"earnings",
+		"parser_version",
+		"imported_at",
+	}))
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(results); i++ {
+		if _, err := s1.ExecContext(
+			ctx,
+			results[i].RaceID,
+			results[i].OrderOfFinish,
+			results[i].FinishPosition,
+			results[i].FinishStatus,
+			results[i].Demoted,
+			results[i].OriginalPlacing,
+			results[i].DeadHeat,
+			results[i].Bracket,
+			results[i].Draw,
+			results[i].HorseID,
+			results[i].Horse,
+			results[i].Sex,
+			results[i].Age,
+			results[i].NormalizedAge,
+			results[i].Weight,
+			results[i].JockeyID,
+			results[i].Jockey,
+			results[i].Time,
+			results[i].TimeSec,
+			results[i].WinningMargin,
+			results[i].MarginLengths,
+			results[i].LengthsBehind,
+			results[i].SpeedIndex,
+			results[i].Position,
+			results[i].RunningStyle,
+			results[i].SectionalTime,
+			results[i].Odds,
+			results[i].Popularity,
+			results[i].HorseWeight,
+			results[i].BodyWeight,
+			results[i].BodyWeightDelta,
+			results[i].BodyWeightStatus,
+			results[i].Note,
+			results[i].Stable,
+			results[i].TrainerID,
+			results[i].OwnerID,
+			results[i].Earnings,
+			parse.Version,
+			b.importedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	s2, err := b.prepare(ctx, `DELETE FROM result_position WHERE race_id = ?;`)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s2.ExecContext(ctx, id); err != nil {
+		return err
+	}
+
+	s3, err := b.prepare(ctx, `INSERT INTO result_position VALUES (?, ?, ?, ?, ?, ?);`)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(results); i++ {
+		for j := 0; j < len(results[i].Positions); j++ {
+			if _, err := s3.ExecContext(
+				ctx,
+				results[i].RaceID,
+				results[i].HorseID,
+				j+1,
+				results[i].Positions[j],
+				parse.Version,
+				b.importedAt,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *Batch) insertRaceClassifications(ctx context.Context, records []*parse.RaceClassification) error {
+	s1, err := b.prepare(ctx, `INSERT INTO race_classification VALUES (?, ?, ?, ?, ?);`)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(records); i++ {
+		if _, err := s1.ExecContext(
+			ctx,
+			records[i].RaceID,
+			records[i].Scheme,
+			records[i].Code,
+			parse.Version,
+			b.importedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SelectRacesToClassify returns the races in the database with the values
+// which the classification schemes look at.
+func SelectRacesToClassify(ctx context.Context, db *sql.DB) ([]*parse.Race, error) {
+	rows, err := db.QueryContext(ctx, `SELECT id, course, surface, distance, classification_code, class, age_condition FROM race ORDER BY id;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	races := []*parse.Race{}
+
+	for rows.Next() {
+		race := &parse.Race{}
+
+		if err := rows.Scan(&race.ID, &race.Course, &race.Surface, &race.Distance, &race.ClassificationCode, &race.Class, &race.AgeCondition); err != nil {
+			return nil, err
+		}
+
+		races = append(races, race)
+	}
+
+	return races, rows.Err()
+}
+
+// ReplaceRaceClassifications replaces the classifications of all the races,
+// so that the rows of the schemes no longer configured are removed.
+func ReplaceRaceClassifications(ctx context.Context, db *sql.DB, records []*parse.RaceClassification) error {
+	return withBatch(ctx, db, func(b *Batch) error {
+		if _, err := b.tx.ExecContext(ctx, `DELETE FROM race_classification;`); err != nil {
+			return err
+		}
+
+		return b.insertRaceClassifications(ctx, records)
+	})
+}
+
+// InsertWorkouts replaces the workout records and their splits.
+func InsertWorkouts(ctx context.Context, db *sql.DB, workouts []*parse.Workout) error {
+	return withBatch(ctx, db, func(b *Batch) error {
+		return b.InsertWorkouts(ctx, workouts)
+	})
+}
+
+// InsertWorkouts replaces the workout records and their splits in the batch.
+func (b *Batch) InsertWorkouts(ctx context.Context, workouts []*parse.Workout) error {
+	s1, err := b.prepare(ctx, `INSERT OR REPLACE INTO workout VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`)
+	if err != nil {
+		return err
+	}
+
+	s2, err := b.prepare(ctx, `DELETE FROM workout_split WHERE horse_id = ? AND date = ?;`)
+	if err != nil {
+		return err
+	}
+
+	s3, err := b.prepare(ctx, `INSERT INTO workout_split VALUES (?, ?, ?, ?, ?, ?, ?);`)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(workouts); i++ {
+		if _, err := s1.ExecContext(
+			ctx,
+			workouts[i].HorseID,
+			workouts[i].Date,
+			workouts[i].RaceID,
+			workouts[i].Course,
+			workouts[i].CourseType,
+			workouts[i].SurfaceState,
+			workouts[i].Rider,
+			workouts[i].Intensity,
+			parse.Version,
+			b.importedAt,
+		); err != nil {
+			return err
+		}
+
+		if _, err := s2.ExecContext(ctx, workouts[i].HorseID, workouts[i].Date); err != nil {
+			return err
+		}
+
+		for j := 0; j < len(workouts[i].Splits); j++ {
+			if _, err := s3.ExecContext(
+				ctx,
+				workouts[i].HorseID,
+				workouts[i].Date,
+				workouts[i].Splits[j].Furlong,
+				workouts[i].Splits[j].Time,
+				workouts[i].Splits[j].Lap,
+				parse.Version,
+				b.importedAt,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Scope is the rows of a table written from a page.
+type Scope struct {
+	Table string
+	Where string
+	Args  []interface{}
+}
+
+// Row is a row of a table by column.
+type Row map[string]interface{}
+
+// Change is a value changed by importing a page again. Old is nil for the
+// added rows and New is nil for the removed rows, whose Field is empty.
+type Change struct {
+	Table string      `json:"table"`
+	Key   string      `json:"key"`
+	Field string      `json:"field,omitempty"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// SelectRows returns the rows in the scope by primary key, which is joined
+// by "/" if it has several columns.
+func (b *Batch) SelectRows(ctx context.Context, scope *Scope) (map[string]Row, error) {
+	pk, err := b.primaryKey(ctx, scope.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := b.tx.QueryContext(ctx, fmt.Sprintf("SELECT * FROM `%s` WHERE %s;", scope.Table, scope.Where), scope.Args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make(map[string]Row)
+
+	for rows.Next() {
+		values := make([]interface{}, len(names))
+		dest := make([]interface{}, len(names))
+		for i := 0; i < len(values); i++ {
+			dest[i] = &values[i]
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		row := make(Row, len(names))
+		for i := 0; i < len(names); i++ {
+			if v, ok := values[i].([]byte); ok {
+				values[i] = string(v)
+			}
+			row[names[i]] = values[i]
+		}
+
+		key := make([]string, len(pk))
+		for i := 0; i < len(pk); i++ {
+			key[i] = fmt.Sprint(row[pk[i]])
+		}
+
+		records[strings.Join(key, "/")] = row
+	}
+
+	return records, rows.Err()
+}
+
+func (b *Batch) primaryKey(ctx context.Context, table string) ([]string, error) {
+	rows, err := b.tx.QueryContext(ctx, fmt.Sprintf("SELECT name FROM pragma_table_info('%s') WHERE pk > 0 ORDER BY pk;", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+
+	for rows.Next() {
+		var name string
+
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// DiffRows returns the values changed from before to after. The parser
+// version and the import timestamp are ignored, since they always change.
+func DiffRows(table string, before map[string]Row, after map[string]Row) []*Change {
+	keys := make(map[string]bool, len(before))
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	var changes []*Change
+
+	for _, key := range sortedKeys(keys) {
+		oldRow, ok1 := before[key]
+		newRow, ok2 := after[key]
+
+		switch {
+		case !ok1:
+			changes = append(changes, &Change{Table: table, Key: key, New: newRow})
+		case !ok2:
+			changes = append(changes, &Change{Table: table, Key: key, Old: oldRow})
+		default:
+			fields := make(map[string]bool, len(newRow))
+			for field := range oldRow {
+				fields[field] = true
+			}
+			for field := range newRow {
+				fields[field] = true
+			}
+
+			for _, field := range sortedKeys(fields) {
+				if field == "parser_version" || field == "imported_at" {
+					continue
+				}
+
+				if fmt.Sprint(oldRow[field]) != fmt.Sprint(newRow[field]) {
+					changes = append(changes, &Change{Table: table, Key: key, Field: field, Old: oldRow[field], New: newRow[field]})
+				}
+			}
+		}
+	}
+
+	return changes
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,91 @@
+// Package store writes the records built by the parse package into the
+// SQLite database.
+package store
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"os"
+
+	_ "github.com/mattn/go-sqlite3"
+	"golang.org/x/xerrors"
+)
+
+//go:embed schema.sql
+var schema string
+
+// pragmas are set to every connection. WAL lets the dump commands read the
+// database while it is written, and the writes are synced only at the
+// checkpoints of WAL instead of every commit.
+const pragmas = "_journal_mode=WAL&_synchronous=NORMAL&_busy_timeout=10000&_cache_size=-65536"
+
+// RacePageDir is the directory of the dumped race result pages, which are
+// parsed again by Setup to repair the results imported by the older versions.
+var RacePageDir string
+
+// Open opens the database file.
+func Open(dbFilePath string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", "file:"+dbFilePath+"?"+pragmas)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Setup creates the database file with the schema. If the file already
+// exists, the tables added to the schema since it was created are created.
+// If force is true, the existing file is removed first.
+func Setup(ctx context.Context, dbFilePath string, force bool) error {
+	if force {
+		os.Remove(dbFilePath)
+		os.Remove(dbFilePath + "-wal")
+		os.Remove(dbFilePath + "-shm")
+	}
+
+	if _, err := os.Stat(dbFilePath); err == nil {
+		db, err := Open(dbFilePath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if err := migrate(ctx, db); err != nil {
+			return xerrors.Errorf("migrate database failure: %+w", err)
+		}
+
+		if _, err := db.ExecContext(ctx, schema); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	done := false
+
+	db, err := Open(dbFilePath)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		db.Close()
+		if !done {
+			os.Remove(dbFilePath)
+		}
+	}()
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d;`, len(migrations))); err != nil {
+		return err
+	}
+
+	done = true
+
+	return nil
+}
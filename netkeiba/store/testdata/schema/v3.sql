@@ -0,0 +1,254 @@
+CREATE TABLE IF NOT EXISTS `race` (
+    id                  INTEGER PRIMARY KEY,
+    name                TEXT    NOT NULL,
+    course              TEXT    NOT NULL,
+    number              INTEGER NOT NULL,
+    surface             TEXT    NOT NULL,
+    direction           TEXT    NOT NULL,
+    distance            INTEGER NOT NULL,
+    weather             TEXT    NOT NULL,
+    surface_state       TEXT    NOT NULL,
+    surface_index       INTEGER,
+    date                TEXT    NOT NULL,
+    post_time           TEXT    NOT NULL,
+    classification      TEXT    NOT NULL,
+    classification_code TEXT    NOT NULL,
+    field_size          INTEGER,
+    parser_version      INTEGER,
+    imported_at         TEXT
+);
+
+CREATE INDEX IF NOT EXISTS date_idx    ON race(date);
+CREATE INDEX IF NOT EXISTS id_date_idx ON race(id, date);
+
+CREATE TABLE IF NOT EXISTS `result` (
+    race_id            INTEGER  NOT NULL,
+    order_of_finish    TEXT     NOT NULL,
+    finish_position    INTEGER,
+    finish_status      TEXT,
+    demoted            INTEGER  NOT NULL DEFAULT 0,
+    original_placing   INTEGER,
+    bracket            INTEGER  NOT NULL,
+    draw               INTEGER  NOT NULL,
+    horse_id           INTEGER  NOT NULL,
+    horse              TEXT     NOT NULL,
+    sex                TEXT     NOT NULL,
+    age                INTEGER  NOT NULL,
+    weight             REAL     NOT NULL,
+    jockey_id          TEXT     NOT NULL,
+    jockey             TEXT     NOT NULL,
+    time               TEXT,
+    time_sec           INTEGER,
+    winning_margin     TEXT     NOT NULL,
+    speed_index        INTEGER,
+    position           TEXT     NOT NULL,
+    sectional_time     REAL,
+    odds               REAL,
+    popularity         INTEGER,
+    horse_weight       TEXT     NOT NULL,
+    body_weight        INTEGER,
+    body_weight_delta  INTEGER,
+    body_weight_status TEXT,
+    note               TEXT,
+    stable             TEXT     NOT NULL,
+    trainer_id         TEXT     NOT NULL,
+    owner_id           TEXT     NOT NULL,
+    earnings           REAL,
+    parser_version     INTEGER,
+    imported_at        TEXT,
+    PRIMARY KEY (race_id, horse_id),
+    FOREIGN KEY (race_id) REFERENCES race(id),
+    FOREIGN KEY (jockey_id) REFERENCES jockey(id),
+    FOREIGN KEY (trainer_id) REFERENCES trainer(id),
+    FOREIGN KEY (owner_id) REFERENCES owner(id)
+);
+
+CREATE INDEX IF NOT EXISTS race_id_idx               ON result (race_id);
+CREATE INDEX IF NOT EXISTS race_id_horse_id_idx      ON result (race_id, horse_id);
+CREATE INDEX IF NOT EXISTS race_id_jockey_id_idx     ON result (race_id, jockey_id);
+CREATE INDEX IF NOT EXISTS race_id_trainer_id_idx    ON result (race_id, trainer_id);
+CREATE INDEX IF NOT EXISTS race_id_owner_id_idx      ON result (race_id, owner_id);
+CREATE INDEX IF NOT EXISTS horse_id_speed_index_idx ON result (horse_id, speed_index);
+CREATE INDEX IF NOT EXISTS trainer_id_idx            ON result (trainer_id);
+CREATE INDEX IF NOT EXISTS owner_id_idx              ON result (owner_id);
+CREATE INDEX IF NOT EXISTS jockey_id_idx             ON result (jockey_id);
+
+CREATE TABLE IF NOT EXISTS `payout` (
+    race_id        INTEGER NOT NULL,
+    ticket_type    TEXT    NOT NULL,
+    draw           TEXT    NOT NULL,
+    amount         REAL    NOT NULL,
+    popularity     INTEGER NOT NULL,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (race_id, ticket_type, draw),
+    FOREIGN KEY (race_id) REFERENCES race(id)
+);
+
+CREATE TABLE IF NOT EXISTS `horse` (
+    id             TEXT    NOT NULL,
+    name           TEXT    NOT NULL,
+    sire_id        TEXT,
+    dam_id         TEXT,
+    breeder_id     TEXT,
+    breeder        TEXT,
+    birthplace     TEXT,
+    region         TEXT,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (id),
+    FOREIGN KEY (breeder_id) REFERENCES breeder(id)
+);
+
+CREATE INDEX IF NOT EXISTS sire_id_idx ON horse (sire_id);
+CREATE INDEX IF NOT EXISTS dam_id_idx ON horse (dam_id);
+CREATE INDEX IF NOT EXISTS breeder_id_idx ON horse (breeder_id);
+
+CREATE TABLE IF NOT EXISTS `jockey` (
+    id             TEXT    NOT NULL,
+    name           TEXT    NOT NULL,
+    kana           TEXT,
+    affiliation    TEXT,
+    license_year   INTEGER,
+    birth_date     TEXT,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS `jockey_stats` (
+    jockey_id      TEXT    NOT NULL,
+    year           INTEGER NOT NULL,
+    rank           INTEGER,
+    first          INTEGER NOT NULL,
+    second         INTEGER NOT NULL,
+    third          INTEGER NOT NULL,
+    unplaced       INTEGER NOT NULL,
+    win_rate       REAL,
+    earnings       REAL,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (jockey_id, year),
+    FOREIGN KEY (jockey_id) REFERENCES jockey(id)
+);
+
+CREATE TABLE IF NOT EXISTS `trainer` (
+    id             TEXT    NOT NULL,
+    name           TEXT    NOT NULL,
+    kana           TEXT,
+    affiliation    TEXT,
+    license_year   INTEGER,
+    birth_date     TEXT,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS `trainer_stats` (
+    trainer_id     TEXT    NOT NULL,
+    year           INTEGER NOT NULL,
+    rank           INTEGER,
+    first          INTEGER NOT NULL,
+    second         INTEGER NOT NULL,
+    third          INTEGER NOT NULL,
+    unplaced       INTEGER NOT NULL,
+    win_rate       REAL,
+    earnings       REAL,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (trainer_id, year),
+    FOREIGN KEY (trainer_id) REFERENCES trainer(id)
+);
+
+CREATE TABLE IF NOT EXISTS `owner` (
+    id             TEXT    NOT NULL,
+    name           TEXT    NOT NULL,
+    kana           TEXT,
+    affiliation    TEXT,
+    license_year   INTEGER,
+    birth_date     TEXT,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS `owner_stats` (
+    owner_id       TEXT    NOT NULL,
+    year           INTEGER NOT NULL,
+    rank           INTEGER,
+    first          INTEGER NOT NULL,
+    second         INTEGER NOT NULL,
+    third          INTEGER NOT NULL,
+    unplaced       INTEGER NOT NULL,
+    win_rate       REAL,
+    earnings       REAL,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (owner_id, year),
+    FOREIGN KEY (owner_id) REFERENCES owner(id)
+);
+
+CREATE TABLE IF NOT EXISTS `breeder` (
+    id             TEXT    NOT NULL,
+    name           TEXT    NOT NULL,
+    location       TEXT,
+    region         TEXT,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS `breeder_stats` (
+    breeder_id     TEXT    NOT NULL,
+    year           INTEGER NOT NULL,
+    rank           INTEGER,
+    first          INTEGER NOT NULL,
+    second         INTEGER NOT NULL,
+    third          INTEGER NOT NULL,
+    unplaced       INTEGER NOT NULL,
+    win_rate       REAL,
+    earnings       REAL,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (breeder_id, year),
+    FOREIGN KEY (breeder_id) REFERENCES breeder(id)
+);
+
+CREATE TABLE IF NOT EXISTS `workout` (
+    horse_id       TEXT    NOT NULL,
+    date           TEXT    NOT NULL,
+    race_id        INTEGER NOT NULL,
+    course         TEXT    NOT NULL,
+    course_type    TEXT    NOT NULL,
+    surface_state  TEXT,
+    rider          TEXT,
+    intensity      TEXT,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (horse_id, date),
+    FOREIGN KEY (horse_id) REFERENCES horse(id),
+    FOREIGN KEY (race_id) REFERENCES race(id)
+);
+
+CREATE INDEX IF NOT EXISTS workout_race_id_idx ON workout (race_id);
+
+CREATE TABLE IF NOT EXISTS `workout_split` (
+    horse_id       TEXT    NOT NULL,
+    date           TEXT    NOT NULL,
+    furlong        INTEGER NOT NULL,
+    time           REAL    NOT NULL,
+    lap            REAL,
+    parser_version INTEGER,
+    imported_at    TEXT,
+    PRIMARY KEY (horse_id, date, furlong),
+    FOREIGN KEY (horse_id, date) REFERENCES workout(horse_id, date)
+);
+
+CREATE TABLE IF NOT EXISTS `import_log` (
+    path           TEXT    PRIMARY KEY,
+    size           INTEGER NOT NULL,
+    mtime          TEXT    NOT NULL,
+    hash           TEXT    NOT NULL,
+    parser_version INTEGER NOT NULL,
+    imported_at    TEXT    NOT NULL
+);